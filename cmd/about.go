@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/ethernautdao/evm-runners-cli/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -20,11 +21,12 @@ var aboutCmd = &cobra.Command{
                                                                  `)
 
 		fmt.Println("A terminal-based game for developers with EVM-based levels")
-		fmt.Println("\nSponsored by \x1b[94m@EthernautDAO\x1b[0m and \x1b[94m@Optimism\x1b[0m")
-		fmt.Println("Authors: \x1b[94m@0xkarmacoma\x1b[0m, \x1b[94m@beskay0x\x1b[0m, \x1b[94m@kyre_rs\x1b[0m")
+		theme := utils.ActiveTheme()
+		fmt.Println("\nSponsored by " + theme.Link("@EthernautDAO") + " and " + theme.Link("@Optimism"))
+		fmt.Println("Authors: " + theme.Link("@0xkarmacoma") + ", " + theme.Link("@beskay0x") + ", " + theme.Link("@kyre_rs"))
 		fmt.Println("")
-		fmt.Println("Website: \x1b[94mhttps://evmr.sh\x1b[0m")
-		fmt.Println("Discord: \x1b[94mhttps://discord.gg/2TwURWvnVT\x1b[0m")
+		fmt.Println("Website: " + theme.Link("https://evmr.sh"))
+		fmt.Println("Discord: " + theme.Link("https://discord.gg/2TwURWvnVT"))
 
 		fmt.Println("\nevm-runners is more than your typical CTF game:")
 		fmt.Println("")
@@ -45,9 +45,9 @@ To update your wallet address, simply run this command again.`,
 
 		fmt.Printf("\nLinking Optimism address '%s' to your account...\n\n", address)
 
-		err = linkWallet(config, address)
+		err = linkWallet(&config, address)
 		if err != nil {
-			return fmt.Errorf("failed to link wallet address: %v", err)
+			return fmt.Errorf("failed to link wallet address: %w", err)
 		} else {
 			fmt.Printf("Success!\n")
 		}
@@ -60,7 +60,7 @@ func init() {
 	rootCmd.AddCommand(addressCmd)
 }
 
-func linkWallet(config utils.Config, address string) error {
+func linkWallet(config *utils.Config, address string) error {
 	// Define the JSON payload
 	jsonPayload := []byte(fmt.Sprintf(`{"address":"%s"}`, address))
 
@@ -68,15 +68,13 @@ func linkWallet(config utils.Config, address string) error {
 	url := config.EVMR_SERVER + "users/wallet"
 	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+config.EVMR_TOKEN)
 
 	// Send the request
 	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, _, err := utils.DoAuthorized(config, req, client)
 	if err != nil {
-		return fmt.Errorf("error sending the request: %v", err)
+		return err
 	}
-	defer resp.Body.Close()
 
 	// Check for errors in the response
 	if resp.StatusCode != http.StatusOK {
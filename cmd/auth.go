@@ -1,22 +1,11 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/ethernautdao/evm-runners-cli/internal/utils"
 	"github.com/spf13/cobra"
-	"io"
-	"net/http"
-	"os/exec"
-	"time"
 )
 
-type AuthResponse struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	AccessToken string `json:"access_token"`
-}
-
 type Config struct {
 	EVMR_SERVER string `mapstructure:"EVMR_SERVER"`
 	EVMR_TOKEN  string `mapstructure:"EVMR_TOKEN"`
@@ -25,116 +14,88 @@ type Config struct {
 }
 
 var authCmd = &cobra.Command{
-	Use:   "auth discord",
-	Short: "Authenticate your account with Discord",
-	Long: `Authenticate your account with Discord.
-Currently, Discord is the only available platform for authentication.
-
-If your Discord username has changed and you want to update it, run 
-'evmr auth discord' again.`,
+	Use:       "auth [discord|github|ethereum]",
+	Short:     "Authenticate your account",
+	ValidArgs: []string{"discord", "github", "ethereum"},
+	Args:      cobra.MaximumNArgs(1),
+	Long: `Authenticate your account with Discord, GitHub or an Ethereum wallet.
+Without an argument, the platform you authenticated with last is used,
+or Discord if you haven't authenticated before.
+
+If your username has changed and you want to update it, run 'evmr auth' again.
+
+Discord and GitHub:
+  A URL and a short code are printed. Open the URL on any device, e.g. when
+  you're connected via SSH, log in and enter the code. The browser is opened
+  automatically if possible, use '--no-browser' to disable this. The browser
+  can be set with the BROWSER environment variable.
+
+Ethereum (Sign-In with Ethereum):
+  A message with a nonce from the server is signed with your key. The key is
+  read from a keystore file ('--keystore'), a file with a hex encoded private
+  key ('--key-file') or the EVMR_PRIVATE_KEY environment variable. The keystore
+  password is read from EVMR_KEYSTORE_PASSWORD or asked for.`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-
-		// commenting this out until more platforms to auth are available
-		//if len(args) == 0 {
-		//	return fmt.Errorf("Please provide a platform, e.g. Discord\n")
-		//}
-
 		// load config
 		config, err := utils.LoadConfig()
 		if err != nil {
 			return err
 		}
 
-		// discord authentication
-		if args[0] == ("discord") || args[0] == ("d") || args[0] == ("Discord") || args[0] == ("") {
-			// Check if user authenticated before
-			if config.EVMR_TOKEN != "" || config.EVMR_ID != "" || config.EVMR_NAME != "" {
-				var overwrite string
-				fmt.Printf("It seems like you authenticated before as '%s'\n\nDo you want to update your info? (y/n): ", config.EVMR_NAME)
-				fmt.Scanln(&overwrite)
-				if overwrite != "y" && overwrite != "Y" {
-					fmt.Println("\nAborting authentication")
-					return nil
-				}
-			}
+		name := config.EVMR_AUTH_PROVIDER
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if name == "" {
+			name = utils.DefaultAuthProvider
+		}
 
-			err := authDiscord(config)
-			if err != nil {
-				return fmt.Errorf("failed to authenticate with Discord: %v", err)
+		provider, err := utils.GetAuthProvider(name)
+		if err != nil {
+			return err
+		}
+
+		// Check if user authenticated before. The stored token is replaced anyway, so it doesn't matter if it can't be loaded.
+		_ = utils.LoadCredentials(&config)
+		if config.EVMR_TOKEN != "" || config.EVMR_ID != "" || config.EVMR_NAME != "" {
+			var overwrite string
+			fmt.Printf("It seems like you authenticated before as '%s'\n\nDo you want to update your info? (y/n): ", config.EVMR_NAME)
+			fmt.Scanln(&overwrite)
+			if overwrite != "y" && overwrite != "Y" {
+				fmt.Println("\nAborting authentication")
+				return nil
 			}
+		}
+
+		noBrowser, _ := cmd.Flags().GetBool("no-browser")
+		keystore, _ := cmd.Flags().GetString("keystore")
+		keyFile, _ := cmd.Flags().GetString("key-file")
 
-			fmt.Println("\nSuccessfully authenticated with Discord!")
-		} else {
-			return fmt.Errorf("Invalid authentication method!\n")
+		opts := utils.AuthOptions{
+			NoBrowser: noBrowser,
+			Keystore:  keystore,
+			KeyFile:   keyFile,
 		}
 
-		return nil
+		return authenticate(config, provider, opts)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(authCmd)
-}
-
-func openBrowser(url string) error {
-	// wait for one second before opening the url
-	time.Sleep(1 * time.Second)
 
-	// open url
-	cmd := exec.Command("open", url)
-	return cmd.Start()
+	authCmd.Flags().Bool("no-browser", false, "Don't open a browser, only print the URL to open")
+	authCmd.Flags().String("keystore", "", "Keystore file of the Ethereum account to sign in with")
+	authCmd.Flags().String("key-file", "", "File with the hex encoded private key of the Ethereum account to sign in with")
 }
 
-func authDiscord(config utils.Config) error {
-	// get URL to open in the browser
-	url := config.EVMR_SERVER + "auth"
-	fmt.Printf("Opening %s in your default browser...\n", url)
-	if err := openBrowser(url); err != nil {
-		return fmt.Errorf("failed to open URL: %v", err)
-	}
-
-	fmt.Println("When you're done authenticating, enter the provided PIN code")
-
-	// read PIN from stdin
-	var pin string
-	fmt.Printf("\nPIN: ")
-	if _, err := fmt.Scanln(&pin); err != nil {
-		return fmt.Errorf("failed to read PIN: %v", err)
-	}
-
-	// make GET request to server
-	tokenUrl := fmt.Sprintf("%susers/info/%s", config.EVMR_SERVER, pin)
-	resp, err := http.Get(tokenUrl)
+func authenticate(config utils.Config, provider utils.AuthProvider, opts utils.AuthOptions) error {
+	authResp, err := utils.Authenticate(config, provider, opts)
 	if err != nil {
-		return fmt.Errorf("error making GET request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to authenticate with server: %s", resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("error reading response body: %v", err)
-	}
-
-	// unmarshal response body
-	var authResp AuthResponse
-	if err := json.Unmarshal(body, &authResp); err != nil {
-		return fmt.Errorf("error unmarshalling response body: %v", err)
-	}
-
-	// set or overwrite config elements
-	config.EVMR_ID = authResp.ID
-	config.EVMR_NAME = fmt.Sprintf("%s", authResp.Name)
-	config.EVMR_TOKEN = authResp.AccessToken
-
-	// save config
-	if err := utils.WriteConfig(config); err != nil {
-		return fmt.Errorf("failed to save auth data: %v", err)
+		return fmt.Errorf("failed to authenticate with %s: %v", provider.DisplayName(), err)
 	}
 
+	fmt.Printf("\nSuccessfully authenticated with %s as '%s'!\n", provider.DisplayName(), authResp.Name)
 	return nil
 }
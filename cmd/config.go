@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ethernautdao/evm-runners-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and edit your configuration",
+	Long: `View and edit your configuration.
+
+Values are read from these sources, each overriding the previous ones:
+defaults < config file < profile config file < credential store < environment variables < flags
+
+Changes are written to the config file of the active profile.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a config key",
+	Args:  cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := utils.GetConfigKey(args[0])
+		if err != nil {
+			return err
+		}
+
+		config, err := utils.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		// credentials are only read from the credential store when needed
+		if key.Credential {
+			if err := utils.LoadCredentials(&config); err != nil {
+				return err
+			}
+		}
+
+		fmt.Println(config.Get(key.Name))
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set the value of a config key",
+	Args:  cobra.ExactArgs(2),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := utils.GetConfigKey(args[0])
+		if err != nil {
+			return err
+		}
+
+		value, err := utils.SetConfigValue(key.Name, args[1])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s=%s\n", key.Name, key.Display(value))
+		return nil
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a config key",
+	Args:  cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := utils.GetConfigKey(args[0])
+		if err != nil {
+			return err
+		}
+
+		if err := utils.UnsetConfigValue(key.Name); err != nil {
+			return err
+		}
+
+		fmt.Printf("Removed %s\n", key.Name)
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all config keys and their values",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+		showOrigin, _ := cmd.Flags().GetBool("show-origin")
+
+		config, origins, err := utils.LoadConfigWithOrigins()
+		if err != nil {
+			return err
+		}
+
+		for _, key := range utils.ConfigKeys {
+			value := config.Get(key.Name)
+			if !showSecrets {
+				value = key.Display(value)
+			}
+
+			if showOrigin {
+				origin := origins[key.Name]
+				if origin == "" {
+					origin = "unset"
+				}
+				fmt.Printf("%-24s%-40s%s\n", key.Name, value, utils.ActiveTheme().Muted(origin))
+			} else {
+				fmt.Printf("%-24s%s\n", key.Name, value)
+			}
+		}
+
+		return nil
+	},
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the path of the config file",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := utils.ActiveConfigFilePath()
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(path)
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config file in your editor",
+	Long: `Open the config file in your editor.
+
+The editor is taken from the VISUAL or EDITOR environment variable, defaulting to 'vi'.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := utils.ActiveConfigFilePath()
+		if err != nil {
+			return err
+		}
+
+		editor := os.Getenv("VISUAL")
+		if editor == "" {
+			editor = os.Getenv("EDITOR")
+		}
+		if editor == "" {
+			editor = "vi"
+		}
+
+		// the editor variable can contain arguments, e.g. "code --wait"
+		editorArgs := strings.Fields(editor)
+		execCmd := exec.Command(editorArgs[0], append(editorArgs[1:], path)...)
+		execCmd.Stdin = os.Stdin
+		execCmd.Stdout = os.Stdout
+		execCmd.Stderr = os.Stderr
+		if err := execCmd.Run(); err != nil {
+			return fmt.Errorf("error running editor '%s': %v", editor, err)
+		}
+
+		// validate the edited config
+		config, err := utils.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("config file is invalid after editing: %v", err)
+		}
+
+		for _, key := range utils.ConfigKeys {
+			value := config.Get(key.Name)
+			if key.Validate == nil || value == "" {
+				continue
+			}
+
+			if _, err := key.Validate(value); err != nil {
+				fmt.Printf("Warning: %s: %v", key.Name, err)
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configEditCmd)
+
+	configListCmd.Flags().Bool("show-secrets", false, "Show secret values like the auth token unmasked")
+	configListCmd.Flags().Bool("show-origin", false, "Show where each value came from (default, file, profile, credential store, env or flag)")
+}
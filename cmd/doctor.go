@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethernautdao/evm-runners-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+type checkResult struct {
+	Name   string
+	Status string
+	Detail string
+	Hint   string
+}
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check your environment for common problems",
+	Long: `Check your environment for common problems.
+
+The following checks are performed:
+  - Required tools (forge) and optional compilers (solc, vyper, huffc)
+  - The config file and the levels directory
+  - Whether your auth token is still accepted by the server
+  - The terminal width
+  - Whether the levels directory is behind its remote`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var results []checkResult
+
+		// tools
+		results = append(results, checkTool("forge", true, "--version"))
+		results = append(results, checkTool("solc", false, "--version"))
+		results = append(results, checkTool("vyper", false, "--version"))
+		results = append(results, checkTool("huffc", false, "--version"))
+
+		// config file
+		configResult, config, configOk := checkConfig()
+		results = append(results, configResult)
+
+		if configOk {
+			results = append(results, checkLevelsDir(config))
+			results = append(results, checkToken(config))
+			results = append(results, checkLevelsUpToDate(config))
+		}
+
+		results = append(results, checkTerminalWidth())
+
+		printReport(results)
+
+		return nil
+	},
+}
+
+// checks if a tool is installed and returns its version
+func checkTool(name string, required bool, versionArg string) checkResult {
+	result := checkResult{Name: name}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		result.Detail = "not found in PATH"
+		if required {
+			result.Status = checkFail
+		} else {
+			result.Status = checkWarn
+		}
+		result.Hint = toolHint(name)
+		return result
+	}
+
+	output, err := exec.Command(path, versionArg).CombinedOutput()
+	if err != nil {
+		result.Status = checkWarn
+		result.Detail = fmt.Sprintf("found at '%s', but '%s %s' failed", path, name, versionArg)
+		result.Hint = toolHint(name)
+		return result
+	}
+
+	result.Status = checkPass
+	result.Detail = lastNonEmptyLine(string(output))
+	return result
+}
+
+func toolHint(name string) string {
+	switch name {
+	case "forge":
+		return "Install Foundry with 'curl -L https://foundry.paradigm.xyz | bash' and run 'foundryup'"
+	case "solc":
+		return "Only needed for Yul solutions, see https://docs.soliditylang.org/en/latest/installing-solidity.html"
+	case "vyper":
+		return "Only needed for Vyper solutions, install it with 'pip install vyper'"
+	case "huffc":
+		return "Only needed for Huff solutions, see https://docs.huff.sh/get-started/installing/"
+	}
+	return ""
+}
+
+// checks if the config can be loaded
+func checkConfig() (checkResult, utils.Config, bool) {
+	result := checkResult{Name: "config"}
+
+	path, err := utils.ConfigFilePath()
+	if err != nil {
+		result.Status = checkFail
+		result.Detail = err.Error()
+		return result, utils.Config{}, false
+	}
+
+	config, err := utils.LoadConfig()
+	if err != nil {
+		result.Status = checkFail
+		result.Detail = strings.TrimSpace(err.Error())
+		if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+			result.Hint = "Run 'evmr init' to create it"
+		} else {
+			result.Hint = fmt.Sprintf("Check the contents of '%s' or run 'evmr init' again", path)
+		}
+		return result, config, false
+	}
+
+	if config.EVMR_SERVER == "" {
+		result.Status = checkFail
+		result.Detail = "EVMR_SERVER is not set"
+		result.Hint = "Run 'evmr init' again"
+		return result, config, false
+	}
+
+	result.Status = checkPass
+	result.Detail = path
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		result.Detail = "no config file, using environment variables and flags"
+	}
+	return result, config, true
+}
+
+// checks if the levels directory exists and contains the expected files
+func checkLevelsDir(config utils.Config) checkResult {
+	result := checkResult{Name: "levels directory"}
+
+	if config.EVMR_LEVELS_DIR == "" {
+		result.Status = checkFail
+		result.Detail = "EVMR_LEVELS_DIR is not set"
+		result.Hint = "Run 'evmr init' again"
+		return result
+	}
+
+	if _, err := os.Stat(config.EVMR_LEVELS_DIR); os.IsNotExist(err) {
+		result.Status = checkFail
+		result.Detail = fmt.Sprintf("'%s' does not exist", config.EVMR_LEVELS_DIR)
+		result.Hint = "Run 'evmr init' again"
+		return result
+	}
+
+	var missing []string
+	for _, entry := range []string{"levels.toml", "template", "src"} {
+		if _, err := os.Stat(filepath.Join(config.EVMR_LEVELS_DIR, entry)); os.IsNotExist(err) {
+			missing = append(missing, entry)
+		}
+	}
+
+	if len(missing) > 0 {
+		result.Status = checkFail
+		result.Detail = fmt.Sprintf("'%s' is missing %s", config.EVMR_LEVELS_DIR, strings.Join(missing, ", "))
+		result.Hint = "Run 'evmr update', or delete the directory and run 'evmr init' again"
+		return result
+	}
+
+	result.Status = checkPass
+	result.Detail = config.EVMR_LEVELS_DIR
+	return result
+}
+
+// checks if the auth token is still accepted by the server
+func checkToken(config utils.Config) checkResult {
+	result := checkResult{Name: "authentication"}
+
+	if err := utils.LoadCredentials(&config); err != nil {
+		result.Status = checkFail
+		result.Detail = strings.TrimSpace(err.Error())
+		result.Hint = "Check EVMR_CREDENTIAL_STORE with 'evmr config list --show-origin'"
+		return result
+	}
+
+	if config.EVMR_TOKEN == "" {
+		result.Status = checkWarn
+		result.Detail = "not authenticated"
+		result.Hint = "Run 'evmr auth' to be able to submit solutions"
+		return result
+	}
+
+	req, _ := http.NewRequest("GET", config.EVMR_SERVER+"submissions/user/", nil)
+	req.Header.Set("Authorization", "Bearer "+config.EVMR_TOKEN)
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Status = checkWarn
+		result.Detail = fmt.Sprintf("could not reach '%s'", config.EVMR_SERVER)
+		result.Hint = "Check your internet connection and the EVMR_SERVER setting"
+		return result
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusTooManyRequests:
+		result.Status = checkPass
+		result.Detail = fmt.Sprintf("logged in as '%s'", config.EVMR_NAME)
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		result.Status = checkFail
+		result.Detail = fmt.Sprintf("token was rejected by the server (%s)", resp.Status)
+		result.Hint = "Run 'evmr auth' again"
+	default:
+		result.Status = checkWarn
+		result.Detail = fmt.Sprintf("unexpected server response (%s)", resp.Status)
+	}
+
+	return result
+}
+
+// checks if the levels directory is behind its remote
+func checkLevelsUpToDate(config utils.Config) checkResult {
+	result := checkResult{Name: "levels up to date"}
+
+	update, err := utils.FetchLevelsUpdate(config.EVMR_LEVELS_DIR, nil)
+	if err != nil {
+		result.Status = checkWarn
+		result.Detail = strings.ReplaceAll(strings.TrimSpace(err.Error()), "\n", " ")
+		return result
+	}
+
+	if update != nil {
+		result.Status = checkWarn
+		result.Detail = fmt.Sprintf("%d commit(s) behind remote", update.Commits)
+		if len(update.Modified) > 0 {
+			result.Detail += fmt.Sprintf(", %d locally modified file(s)", len(update.Modified))
+		}
+		result.Hint = "Run 'evmr update' to get the latest levels"
+		return result
+	}
+
+	result.Status = checkPass
+	result.Detail = "up to date with remote"
+	return result
+}
+
+// checks if the terminal is wide enough to show all columns of the TUI
+func checkTerminalWidth() checkResult {
+	result := checkResult{Name: "terminal width"}
+
+	width, _ := utils.TerminalSize()
+	if width == 0 {
+		result.Status = checkPass
+		result.Detail = "not a terminal"
+		return result
+	}
+
+	fullWidth := 80
+	if width < fullWidth {
+		result.Status = checkWarn
+		result.Detail = fmt.Sprintf("%d columns, level lists and leaderboards hide some columns", width)
+		result.Hint = fmt.Sprintf("Resize your terminal to at least %d columns to see all columns", fullWidth)
+		return result
+	}
+
+	result.Status = checkPass
+	result.Detail = fmt.Sprintf("%d columns", width)
+	return result
+}
+
+func printReport(results []checkResult) {
+	var passed, warnings, failures int
+
+	theme := utils.ActiveTheme()
+
+	for _, r := range results {
+		var label string
+		switch r.Status {
+		case checkPass:
+			label = theme.Pass("[PASS]")
+			passed++
+		case checkWarn:
+			label = theme.Warn("[WARN]")
+			warnings++
+		case checkFail:
+			label = theme.Fail("[FAIL]")
+			failures++
+		}
+
+		fmt.Printf("%s %-20s%s\n", label, r.Name, r.Detail)
+		if r.Hint != "" && r.Status != checkPass {
+			fmt.Println(theme.Muted("       -> " + r.Hint))
+		}
+	}
+
+	fmt.Printf("\n%d passed, %d warning(s), %d failure(s)\n", passed, warnings, failures)
+}
+
+// returns the last non-empty line of a command output
+func lastNonEmptyLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
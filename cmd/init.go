@@ -5,8 +5,6 @@ import (
 	"github.com/ethernautdao/evm-runners-cli/internal/utils"
 	"github.com/spf13/cobra"
 	"os"
-	"os/exec"
-	"os/user"
 	"path/filepath"
 )
 
@@ -16,20 +14,29 @@ var initCmd = &cobra.Command{
 	Short: "Initialize evm-runners",
 	Long: `Initialize evm-runners by
 
-1. Cloning the 'ethernautdao/evm-runners-levels.git' repository into './evmr-levels'.
-2. Creating a .env file in '~/.config/evm-runners/'.`,
+1. Cloning the 'ethernautdao/evm-runners-levels.git' repository into './evm-runners'.
+2. Creating a .env file in '~/.evm-runners/'.
+
+The levels can be installed from another source with '--repo', which accepts
+  - the URL of a git repository, e.g. a private level pack
+  - the path of a local git repository or directory, e.g. an offline mirror
+  - the path or URL of a tarball (.tar.gz, .tgz or .tar)
+
+Use '--ref' to clone a branch, tag or commit of a git repository, and '--dir' to choose
+the levels directory. Only levels installed from git repositories can be updated
+with 'evmr update'.
+
+The config directory can be changed with EVMR_HOME or XDG_CONFIG_HOME.`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get user's home directory
-		usr, err := user.Current()
-		if err != nil {
-			return fmt.Errorf("error getting user's home directory: %v", err)
-		}
+		repo, _ := cmd.Flags().GetString("repo")
+		ref, _ := cmd.Flags().GetString("ref")
+		dir, _ := cmd.Flags().GetString("dir")
 
 		// Get absolute path for evm-runners
-		subdir, err := filepath.Abs("evm-runners")
+		subdir, err := filepath.Abs(dir)
 		if err != nil {
-			return fmt.Errorf("error getting absolute path for evm-runners: %v", err)
+			return fmt.Errorf("error getting absolute path for '%s': %v", dir, err)
 		}
 
 		// Ask user if they want to init evm-runners in the current directory
@@ -49,17 +56,24 @@ var initCmd = &cobra.Command{
 			return nil
 		}
 
-		// Clone ethernautdao/evm-runners-levels.git
-		fmt.Printf("Cloning 'github.com/ethernautdao/evm-runners-levels.git' ...\n")
+		// Clone ethernautdao/evm-runners-levels.git or the given source
+		if ref != "" {
+			fmt.Printf("Installing levels from '%s' at '%s' ...\n", repo, ref)
+		} else {
+			fmt.Printf("Installing levels from '%s' ...\n", repo)
+		}
 
-		err = cloneRepository(subdir)
+		err = installLevels(repo, ref, subdir)
 		if err != nil {
 			return err
 		}
 
 		// Create or update .env file
-		envDirPath := filepath.Join(usr.HomeDir, ".evm-runners")
-		envFilePath := filepath.Join(envDirPath, ".env")
+		envFilePath, err := utils.ConfigFilePath()
+		if err != nil {
+			return err
+		}
+		envDirPath := filepath.Dir(envFilePath)
 
 		fmt.Printf("\nUpdating .env file at '%s' ...\n", envFilePath)
 
@@ -68,22 +82,33 @@ var initCmd = &cobra.Command{
 			return err
 		}
 
+		// move solutions of an existing levels directory out of src/
+		config, err := utils.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		message, err := utils.MigrateSolutions(config)
+		if err != nil {
+			return err
+		}
+		if message != "" {
+			fmt.Printf("\n%s", message)
+		}
+
 		fmt.Println("\nevm-runners initialized successfully!\nRun 'evmr start' to begin solving a level!")
 		return nil
 	},
 }
 
-func cloneRepository(subdir string) error {
+func installLevels(source string, ref string, subdir string) error {
 	if _, err := os.Stat(subdir); os.IsNotExist(err) {
-		execCmd := exec.Command("git", "clone", "https://github.com/ethernautdao/evm-runners-levels.git", subdir)
-		execCmd.Stdout = os.Stdout
-		execCmd.Stderr = os.Stderr
-		if err := execCmd.Run(); err != nil {
-			return fmt.Errorf("error cloning the repository: %v", err)
+		if err := utils.InstallLevels(source, ref, subdir); err != nil {
+			return err
 		}
-		fmt.Println("evm-runners-levels cloned successfully")
+		fmt.Println("Levels installed successfully")
 	} else {
-		fmt.Println("evm-runners already exists")
+		fmt.Printf("'%s' already exists, keeping the installed levels\n", subdir)
 	}
 
 	return nil
@@ -93,7 +118,7 @@ func createOrUpdateEnv(subdir string, envDirPath string, envFilePath string) err
 
 	// Set the fields in the config struct
 	config := utils.Config{
-		EVMR_SERVER:     "https://api.evmr.sh/",
+		EVMR_SERVER:     utils.DefaultServer,
 		EVMR_LEVELS_DIR: subdir,
 	}
 
@@ -107,7 +132,7 @@ func createOrUpdateEnv(subdir string, envDirPath string, envFilePath string) err
 		}
 
 		// create the .env file
-		f, err := os.Create(envFilePath)
+		f, err := os.OpenFile(envFilePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 		if err != nil {
 			return fmt.Errorf("error creating .env file: %v", err)
 		}
@@ -145,4 +170,8 @@ func createOrUpdateEnv(subdir string, envDirPath string, envFilePath string) err
 
 func init() {
 	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().String("repo", utils.LevelsRepoURL, "Git repository, local directory or tarball to install the levels from")
+	initCmd.Flags().String("ref", "", "Branch, tag or commit of the git repository")
+	initCmd.Flags().String("dir", "evm-runners", "Directory to install the levels into")
 }
@@ -1,14 +1,11 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/ethernautdao/evm-runners-cli/internal/tui"
 	"github.com/ethernautdao/evm-runners-cli/internal/utils"
 	"github.com/spf13/cobra"
-	"io"
-	"net/http"
 )
 
 var leaderboardCmd = &cobra.Command{
@@ -38,72 +35,41 @@ var leaderboardCmd = &cobra.Command{
 			return nil
 		}
 
-		return displayLeaderboard(levels[level].ID)
-	},
-}
-
-func fetchLeaderboardData(url string) ([]utils.SubmissionData, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("error making GET request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http request failed with status: %s", resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %v", err)
-	}
-
-	var leaderboardData []utils.SubmissionData
-	if err := json.Unmarshal(body, &leaderboardData); err != nil {
-		return nil, fmt.Errorf("error decoding JSON response: %v", err)
-	}
-
-	// Limit the array length to 10 if it exceeds
-	if len(leaderboardData) > 10 {
-		leaderboardData = leaderboardData[:10]
-	}
+		// use the server of the level's pack
+		config, err = utils.PackConfig(config, levels[level].Pack)
+		if err != nil {
+			return err
+		}
 
-	return leaderboardData, nil
+		return displayLeaderboard(config, levels[level].ID)
+	},
 }
 
-func displayLeaderboard(levelId string) error {
-	config, err := utils.LoadConfig()
-	if err != nil {
-		return fmt.Errorf("error loading config: %v", err)
-	}
-
-	gasURL := fmt.Sprintf("%ssubmissions/leaderboard/gas/%s", config.EVMR_SERVER, levelId)
-	sizeURL := fmt.Sprintf("%ssubmissions/leaderboard/size/%s", config.EVMR_SERVER, levelId)
-
+func displayLeaderboard(config utils.Config, levelId string) error {
 	// Fetch gas leaderboard data
-	gasLeaderboardData, err := fetchLeaderboardData(gasURL)
+	gasLeaderboardData, err := utils.FetchLeaderboard(config.EVMR_SERVER, "gas", levelId)
 	if err != nil {
 		return fmt.Errorf("error fetching gas leaderboard data: %v", err)
 	}
 
 	// Fetch size leaderboard data
-	sizeLeaderboardData, err := fetchLeaderboardData(sizeURL)
+	sizeLeaderboardData, err := utils.FetchLeaderboard(config.EVMR_SERVER, "size", levelId)
 	if err != nil {
 		return fmt.Errorf("error fetching size leaderboard data: %v", err)
 	}
 
-	// Initialize the BubbleTea UI
-	gasUI, err := tui.NewLeaderboardUI(gasLeaderboardData, "gas")
-	if err != nil {
-		// can only fail if terminal width is < required width, so we bubble up error
-		return err
+	// Limit the leaderboards to the top 10
+	if len(gasLeaderboardData) > 10 {
+		gasLeaderboardData = gasLeaderboardData[:10]
 	}
-	sizeUI, err := tui.NewLeaderboardUI(sizeLeaderboardData, "size")
-	if err != nil {
-		// can only fail if terminal width is < required width, so we bubble up error
-		return err
+	if len(sizeLeaderboardData) > 10 {
+		sizeLeaderboardData = sizeLeaderboardData[:10]
 	}
 
+	// Initialize the BubbleTea UI
+	gasUI := tui.NewLeaderboardUI(gasLeaderboardData, "gas")
+	sizeUI := tui.NewLeaderboardUI(sizeLeaderboardData, "size")
+
 	// Combine the views of gasUI and sizeUI
 	m := &tui.CombinedLeaderboardUI{GasUI: gasUI, SizeUI: sizeUI}
 
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ethernautdao/evm-runners-cli/internal/tui"
+	"github.com/ethernautdao/evm-runners-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// levelCmd represents the level command
+var levelCmd = &cobra.Command{
+	Use:   "level",
+	Short: "Show information about a level",
+}
+
+var levelShowCmd = &cobra.Command{
+	Use:   "show <level>",
+	Short: "Show the description, interface and scores of a level",
+	Long: `Show the description of a level, the interface your solution has to implement,
+the number of solves, your scores and the top scores of the level.`,
+	Args: cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		showHints, _ := cmd.Flags().GetBool("hints")
+
+		// load config
+		config, err := utils.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		// load levels
+		levels, err := utils.LoadLevels()
+		if err != nil {
+			return fmt.Errorf("error loading levels: %v", err)
+		}
+
+		level, err := utils.ResolveLevel(levels, args[0])
+		if err != nil {
+			return err
+		}
+
+		details := utils.GetLevelDetails(config, levels[level])
+
+		width, _ := utils.TerminalSize()
+		if width == 0 {
+			width = 80
+		}
+
+		out, err := tui.RenderMarkdown(tui.LevelDetailMarkdown(level, levels[level], &details, showHints), width)
+		if err != nil {
+			return fmt.Errorf("error rendering level details: %v", err)
+		}
+
+		fmt.Print(out)
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(levelCmd)
+	levelCmd.AddCommand(levelShowCmd)
+
+	levelShowCmd.Flags().Bool("hints", false, "Show the hints of the level")
+}
@@ -6,7 +6,6 @@ import "github.com/ethernautdao/evm-runners-cli/internal/utils"
 import (
 	"fmt"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
 
@@ -31,31 +30,20 @@ var levelsCmd = &cobra.Command{
 		// get amount of solves for each level
 		solves := utils.GetSolves(levels)
 
-		// Initialize the submissions map
-		submissions := make(map[string]string)
-		for key := range levels {
-			submissions[levels[key].Contract] = ""
-		}
-
 		// Fetch existing submission data if user authenticated
-		if config.EVMR_TOKEN != "" {
-			// we explicitly ignore checking the error here
-			sub, _ := utils.FetchSubmissionData(config)
-
-			for _, item := range sub {
-				submissions[item.LevelName] = "x"
-			}
-		}
+		// we explicitly ignore checking the error here
+		submissions, _ := utils.GetSolved(&config, levels)
 
 		// display level list
-		model, err := tui.NewLevelList(levels, solves, submissions)
-		if err != nil {
-			// can only fail if terminal width is < required width, so we bubble up error
-			return err
+		model := tui.NewLevelList(levels, solves, submissions)
+		model.LoadRanks = func() map[string]int {
+			return utils.GetRanks(config, levels, submissions)
+		}
+		model.LoadDetails = func(key string) utils.LevelDetails {
+			return utils.GetLevelDetails(config, levels[key])
 		}
-		p := tea.NewProgram(model)
 
-		if err := p.Start(); err != nil {
+		if err := runProgram(model); err != nil {
 			return fmt.Errorf("error displaying level list: %v", err)
 		}
 
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethernautdao/evm-runners-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// logoutCmd represents the logout command
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Log out of your account",
+	Long: `Log out of your account.
+
+Your auth token is revoked on the server if possible, and your auth tokens,
+user ID and name are removed from your config.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := utils.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		if err := utils.LoadCredentials(&config); err != nil {
+			return err
+		}
+
+		if config.EVMR_TOKEN == "" && config.EVMR_ID == "" && config.EVMR_NAME == "" {
+			fmt.Println("You are not logged in.")
+			return nil
+		}
+
+		if config.EVMR_TOKEN != "" {
+			err := utils.RevokeToken(config)
+			if errors.Is(err, utils.ErrNotSupported) {
+				fmt.Println("The server doesn't support revoking tokens, the token is only removed locally.")
+			} else if err != nil {
+				fmt.Printf("Could not revoke the token on the server (%v), it is only removed locally.\n", err)
+			}
+		}
+
+		for _, key := range []string{"EVMR_TOKEN", "EVMR_REFRESH_TOKEN", "EVMR_ID", "EVMR_NAME"} {
+			if err := utils.UnsetConfigValue(key); err != nil {
+				return fmt.Errorf("error removing %s: %v", key, err)
+			}
+		}
+
+		if os.Getenv("EVMR_TOKEN") != "" {
+			fmt.Println("Note: EVMR_TOKEN is still set in your environment.")
+		}
+
+		if config.EVMR_NAME != "" {
+			fmt.Printf("Logged out '%s'.\n", config.EVMR_NAME)
+		} else {
+			fmt.Println("Logged out.")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logoutCmd)
+}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethernautdao/evm-runners-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// packCmd represents the pack command
+var packCmd = &cobra.Command{
+	Use:   "pack",
+	Short: "Manage level packs",
+	Long: `Manage level packs, e.g. community levels or a private training pack.
+
+Level packs are installed side by side with the official levels. Levels are named by
+their pack, e.g. 'official/average' or 'training/intro'. The pack can be omitted if the
+level name is unique or the level is an official one, e.g. 'evmr start average'.`,
+}
+
+var packListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the installed level packs",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := utils.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		packs, err := utils.LoadPacks(config)
+		if err != nil {
+			return err
+		}
+
+		levels, err := utils.LoadLevels()
+		if err != nil {
+			return fmt.Errorf("error loading levels: %v", err)
+		}
+
+		counts := make(map[string]int)
+		for _, level := range levels {
+			counts[level.Pack]++
+		}
+
+		for _, pack := range packs {
+			server := pack.Server
+			if server == "" {
+				server = config.EVMR_SERVER
+			}
+
+			details := "server: " + server
+			if pack.Source != "" {
+				source := pack.Source
+				if pack.Ref != "" {
+					source += "@" + pack.Ref
+				}
+				details = "source: " + source + ", " + details
+			}
+
+			fmt.Printf("%-16s%-12s%s\n", pack.Name, fmt.Sprintf("%d levels", counts[pack.Name]), pack.Dir)
+			fmt.Printf("%-28s%s\n", "", utils.ActiveTheme().Muted(details))
+		}
+
+		return nil
+	},
+}
+
+var packAddCmd = &cobra.Command{
+	Use:   "add <name> <source>",
+	Short: "Install a level pack",
+	Long: `Install a level pack from a git repository, a local directory or a tarball.
+The source is handled like the '--repo' flag of 'evmr init'.
+
+The pack is installed into '~/.evm-runners/packs/<name>' unless '--dir' is set.
+Use '--server' if the levels of the pack are hosted on another server.`,
+	Args: cobra.ExactArgs(2),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, _ := cmd.Flags().GetString("ref")
+		dir, _ := cmd.Flags().GetString("dir")
+		packServer, _ := cmd.Flags().GetString("server")
+
+		config, err := utils.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		// requests are built by appending the endpoint to the server URL
+		if packServer != "" && !strings.HasSuffix(packServer, "/") {
+			packServer += "/"
+		}
+
+		pack := utils.LevelPack{
+			Name:   args[0],
+			Dir:    dir,
+			Source: args[1],
+			Ref:    ref,
+			Server: packServer,
+		}
+
+		fmt.Printf("Installing level pack '%s' from '%s' ...\n", pack.Name, pack.Source)
+		if err := utils.AddPack(config, pack); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nLevel pack '%s' installed. Run 'evmr levels' to see its levels.\n", strings.ToLower(pack.Name))
+		return nil
+	},
+}
+
+var packRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a level pack",
+	Long: `Remove a level pack. Packs installed into the default directory are deleted,
+packs installed with '--dir' are only unregistered and their files are kept.
+Your solutions are always kept.`,
+	Args: cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pack, err := utils.RemovePack(args[0])
+		if err != nil {
+			return err
+		}
+
+		defaultDir, err := utils.DefaultPackDir(pack.Name)
+		if err != nil {
+			return err
+		}
+
+		if filepath.Clean(pack.Dir) == filepath.Clean(defaultDir) {
+			if err := os.RemoveAll(pack.Dir); err != nil {
+				return fmt.Errorf("error deleting '%s': %v", pack.Dir, err)
+			}
+		} else {
+			fmt.Printf("The files of the pack were kept in '%s'.\n", pack.Dir)
+		}
+
+		fmt.Printf("Level pack '%s' removed.\n", pack.Name)
+		return nil
+	},
+}
+
+var packUpdateCmd = &cobra.Command{
+	Use:   "update [name]",
+	Short: "Update level packs",
+	Long: `Update a level pack, or all packs including the official levels if no pack is given.
+Packs that were not installed from a git repository are skipped. Local modifications
+are handled like in 'evmr update'.`,
+	Args: cobra.MaximumNArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		modified, _ := cmd.Flags().GetString("modified")
+		if modified != "" && modified != utils.ModifiedStash && modified != utils.ModifiedSkip && modified != utils.ModifiedAbort {
+			return fmt.Errorf("invalid value '%s' for --modified, use stash, skip or abort\n", modified)
+		}
+
+		config, err := utils.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		packs, err := utils.LoadPacks(config)
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 1 {
+			pack, err := utils.GetPack(config, args[0])
+			if err != nil {
+				return err
+			}
+			packs = []utils.LevelPack{pack}
+		}
+
+		var failed []string
+		for _, pack := range packs {
+			if !utils.IsGitRepository(pack.Dir) {
+				fmt.Printf("Skipping level pack '%s', it was not installed from a git repository.\n\n", pack.Name)
+				continue
+			}
+
+			fmt.Printf("Updating level pack '%s' ...\n\n", pack.Name)
+
+			packConfig, err := utils.PackConfig(config, pack.Name)
+			if err != nil {
+				return err
+			}
+
+			if err := updateLevels(packConfig, pack.Name, modified); err != nil {
+				// keep updating the other packs
+				fmt.Printf("Error: %v\n", strings.TrimSpace(err.Error()))
+				failed = append(failed, pack.Name)
+			}
+			fmt.Println()
+		}
+
+		if len(failed) > 0 {
+			return fmt.Errorf("failed to update: %s\n", strings.Join(failed, ", "))
+		}
+
+		fmt.Println("Done!")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(packCmd)
+	packCmd.AddCommand(packListCmd)
+	packCmd.AddCommand(packAddCmd)
+	packCmd.AddCommand(packRemoveCmd)
+	packCmd.AddCommand(packUpdateCmd)
+
+	packAddCmd.Flags().String("ref", "", "Branch, tag or commit of the git repository")
+	packAddCmd.Flags().String("dir", "", "Directory to install the pack into")
+	// unlike the global --server flag, this only sets the server of the pack
+	packAddCmd.Flags().String("server", "", "Server URL of the pack, if its levels are hosted on another server")
+	packUpdateCmd.Flags().String("modified", "", "How to handle locally modified files: stash, skip or abort")
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethernautdao/evm-runners-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// profileCmd represents the profile command
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage config profiles",
+	Long: `Manage config profiles, e.g. to switch between servers or accounts.
+
+Each profile has its own server, levels directory and authentication. Settings
+that are not set in a profile are taken from the default profile.
+
+The active profile is selected with the '--profile' flag, the EVMR_PROFILE
+environment variable or 'evmr profile use <name>', in that order.`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all profiles",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := utils.ListProfiles()
+		if err != nil {
+			return err
+		}
+
+		active, err := utils.ActiveProfile()
+		if err != nil {
+			return err
+		}
+
+		for _, profile := range profiles {
+			marker := " "
+			if profile == active {
+				marker = "*"
+			}
+
+			// environment variables and flags would show the same overridden values for every profile
+			config, err := utils.LoadProfileFileConfig(profile)
+			if err != nil {
+				fmt.Printf("%s %-16s%s\n", marker, profile, utils.ActiveTheme().Muted(strings.TrimSpace(err.Error())))
+				continue
+			}
+
+			user := "not authenticated"
+			if config.EVMR_NAME != "" {
+				user = config.EVMR_NAME
+			}
+
+			fmt.Printf("%s %-16s%-30s%s\n", marker, profile, config.EVMR_SERVER, utils.ActiveTheme().Muted(user))
+		}
+
+		return nil
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Select the profile to use",
+	Args:  cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := utils.UseProfile(args[0]); err != nil {
+			return err
+		}
+
+		fmt.Printf("Now using profile '%s'\n", args[0])
+		return nil
+	},
+}
+
+var profileAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a new profile",
+	Long: `Add a new profile. Use '--server' and '--levels-dir' to set the server and levels directory
+of the profile. Settings that are not provided are taken from the default profile.
+
+Run 'evmr --profile <name> auth discord' to authenticate with the new profile.`,
+	Args: cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profileServer, _ := cmd.Flags().GetString("server")
+		profileLevelsDir, _ := cmd.Flags().GetString("levels-dir")
+
+		config := utils.Config{
+			EVMR_SERVER:     profileServer,
+			EVMR_LEVELS_DIR: profileLevelsDir,
+		}
+
+		// requests are built by appending the endpoint to the server URL
+		if config.EVMR_SERVER != "" && !strings.HasSuffix(config.EVMR_SERVER, "/") {
+			config.EVMR_SERVER += "/"
+		}
+
+		if config.EVMR_LEVELS_DIR != "" {
+			absLevelsDir, err := filepath.Abs(config.EVMR_LEVELS_DIR)
+			if err != nil {
+				return fmt.Errorf("error getting absolute path for levels directory: %v", err)
+			}
+			config.EVMR_LEVELS_DIR = absLevelsDir
+		}
+
+		if err := utils.AddProfile(args[0], config); err != nil {
+			return err
+		}
+
+		fmt.Printf("Profile '%s' added. Run 'evmr profile use %s' to select it.\n", args[0], args[0])
+		return nil
+	},
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a profile",
+	Args:  cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := utils.RemoveProfile(args[0]); err != nil {
+			return err
+		}
+
+		fmt.Printf("Profile '%s' removed.\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileAddCmd)
+	profileCmd.AddCommand(profileRemoveCmd)
+
+	// unlike the global flags, these only set the values of the new profile
+	profileAddCmd.Flags().String("server", "", "Server URL of the profile")
+	profileAddCmd.Flags().String("levels-dir", "", "Levels directory of the profile")
+}
@@ -1,9 +1,21 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ethernautdao/evm-runners-cli/internal/tui"
+	"github.com/ethernautdao/evm-runners-cli/internal/utils"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	profile   string
+	server    string
+	levelsDir string
+	noColor   bool
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -24,17 +36,160 @@ How to play:
   3. 'evmr validate <level>' - Test your solution.
   4. 'evmr submit <level>' - Submit your solution.
 
+Run 'evmr' without a command to do all of this in the interactive home screen.
+
 Arguments in <> are required, while arguments in [] are optional.`,
+
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		utils.SetProfile(profile)
+
+		// flags override the config file and environment variables
+		if globalFlagChanged(cmd, "server") {
+			if err := utils.SetConfigFlag("EVMR_SERVER", "server", server); err != nil {
+				return err
+			}
+		}
+		if globalFlagChanged(cmd, "levels-dir") {
+			if err := utils.SetConfigFlag("EVMR_LEVELS_DIR", "levels-dir", levelsDir); err != nil {
+				return err
+			}
+		}
+
+		if noColor {
+			if err := utils.SetConfigFlag("EVMR_THEME", "no-color", utils.ThemeNoColor); err != nil {
+				return err
+			}
+		}
+
+		// move config files to EVMR_HOME or the XDG directories, if set
+		if err := utils.MigrateLegacyDirs(); err != nil {
+			return err
+		}
+
+		utils.LoadTheme()
+
+		// offer to authenticate again if the auth token expired
+		if isInteractive() {
+			utils.Reauthenticate = reauthenticate
+		}
+
+		return nil
+	},
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// the home screen needs a terminal, e.g. in scripts only the help is printed
+		if !isInteractive() {
+			return cmd.Help()
+		}
+
+		return runHome()
+	},
+}
+
+// shows the home screen, which integrates the level list, validation, submission and leaderboards
+func runHome() error {
+	// load config
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	levels, err := utils.LoadLevels()
+	if err != nil {
+		return fmt.Errorf("error loading levels: %v", err)
+	}
+
+	// get amount of solves for each level
+	solves := utils.GetSolves(levels)
+
+	// Fetch existing submission data if user authenticated
+	// we explicitly ignore checking the error here
+	submissions, _ := utils.GetSolved(&config, levels)
+
+	if err := runProgram(tui.NewHome(config, levels, solves, submissions), tea.WithAltScreen()); err != nil {
+		return fmt.Errorf("error displaying home screen: %v", err)
+	}
+
+	return nil
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// reports whether a global flag was set. Commands like 'evmr pack add' have local flags with the same name,
+// which don't override the config.
+func globalFlagChanged(cmd *cobra.Command, name string) bool {
+	flag := cmd.Flags().Lookup(name)
+	return flag != nil && flag.Changed && flag == cmd.Root().PersistentFlags().Lookup(name)
+}
+
+// reports whether the user can answer prompts
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// offers to authenticate again with the platform used last if the auth token expired.
+// Only the request that failed is retried with the new token, see utils.DoAuthorized.
+func reauthenticate(config *utils.Config) error {
+	var answer string
+	fmt.Printf("Your auth token has expired. Do you want to authenticate again and retry? (y/n): ")
+	fmt.Scanln(&answer)
+	if answer != "y" && answer != "Y" {
+		return fmt.Errorf("authentication declined")
+	}
+
+	// the config of the request may be changed, e.g. the levels directory of a pack, so it isn't written
+	current, err := utils.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	name := current.EVMR_AUTH_PROVIDER
+	if name == "" {
+		name = utils.DefaultAuthProvider
+	}
+
+	provider, err := utils.GetAuthProvider(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	authResp, err := utils.Authenticate(current, provider, utils.AuthOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to authenticate with %s: %v", provider.DisplayName(), err)
+	}
+
+	fmt.Printf("\nSuccessfully authenticated with %s as '%s'!\n\n", provider.DisplayName(), authResp.Name)
+
+	config.EVMR_TOKEN = authResp.AccessToken
+	config.EVMR_REFRESH_TOKEN = authResp.RefreshToken
+	config.EVMR_ID = authResp.ID
+	config.EVMR_NAME = authResp.Name
+
+	return nil
+}
+
+// runs a TUI program. Prompts would corrupt the screen, so re-authentication is disabled while it runs.
+func runProgram(model tea.Model, opts ...tea.ProgramOption) error {
+	reauth := utils.Reauthenticate
+	utils.Reauthenticate = nil
+	defer func() { utils.Reauthenticate = reauth }()
+
+	return tea.NewProgram(model, opts...).Start()
+}
+
 func init() {
 	rootCmd.CompletionOptions.HiddenDefaultCmd = true
+
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "The config profile to use (overrides EVMR_PROFILE)")
+	rootCmd.PersistentFlags().StringVar(&server, "server", "", "The server URL to use (overrides EVMR_SERVER)")
+	rootCmd.PersistentFlags().StringVar(&levelsDir, "levels-dir", "", "The levels directory to use (overrides EVMR_LEVELS_DIR)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colors (overrides EVMR_THEME and NO_COLOR)")
 }
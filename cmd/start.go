@@ -4,11 +4,10 @@ import "github.com/ethernautdao/evm-runners-cli/internal/tui"
 import "github.com/ethernautdao/evm-runners-cli/internal/utils"
 
 import (
+	"errors"
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
-	"os"
-	"path/filepath"
 	"strings"
 )
 
@@ -43,6 +42,12 @@ var startCmd = &cobra.Command{
 			return nil
 		}
 
+		// use the levels directory and server of the level's pack
+		config, err = utils.PackConfig(config, levels[level].Pack)
+		if err != nil {
+			return err
+		}
+
 		// get lang
 		lang, err = getLang(lang)
 		if err != nil {
@@ -53,11 +58,20 @@ var startCmd = &cobra.Command{
 			return nil
 		}
 
+		if lang != "no template" && !levels[level].SupportsLanguage(lang) {
+			return fmt.Errorf("Level '%s' has no %s template. Available languages: %s\n", utils.LevelName(level), lang, strings.Join(levels[level].Languages, ", "))
+		}
+
+		solutionsDir, err := utils.SolutionsDir(config)
+		if err != nil {
+			return err
+		}
+
 		if lang != "no template" {
 			filename := levels[level].File
 			fileToCopy := filename + "." + lang
 
-			err = copyTemplateFile(config.EVMR_LEVELS_DIR, fileToCopy)
+			err = copyTemplateFile(config.EVMR_LEVELS_DIR, solutionsDir, fileToCopy)
 			if err != nil {
 				return err
 			}
@@ -65,7 +79,7 @@ var startCmd = &cobra.Command{
 			fmt.Printf("No template file selected.\n\n")
 		}
 
-		fmt.Printf("You can start working on level '%s' in:\n%s\n\nTo validate your solution, run 'evmr validate %s'\n", level, filepath.Join(config.EVMR_LEVELS_DIR, "src"), level)
+		fmt.Printf("You can start working on level '%s' in:\n%s\n\nTo validate your solution, run 'evmr validate %s'\n", utils.LevelName(level), solutionsDir, utils.LevelName(level))
 
 		return nil
 	},
@@ -76,54 +90,34 @@ func GetLevel(args []string, config utils.Config, levels map[string]utils.Level)
 	if len(args) == 0 {
 		solves := utils.GetSolves(levels)
 
-		// Initialize the submissions map
-		submissions := make(map[string]string)
-		for key := range levels {
-			submissions[levels[key].Contract] = ""
-		}
-
 		// Fetch existing submission data if user authenticated
-		if config.EVMR_TOKEN != "" {
-			sub, err := utils.FetchSubmissionData(config)
-			if err != nil {
-				return "", fmt.Errorf("error fetching submission data: %v", err)
-			}
-
-			for _, item := range sub {
-				submissions[item.LevelName] = "x"
-			}
+		submissions, err := utils.GetSolved(&config, levels)
+		if err != nil {
+			return "", fmt.Errorf("error fetching submission data: %w", err)
 		}
 
 		// display level list
-		model, err := tui.NewLevelList(levels, solves, submissions)
-		if err != nil {
-			// can only fail if terminal width is < required width, so we bubble up error
-			return "", err
+		model := tui.NewLevelList(levels, solves, submissions)
+		model.LoadRanks = func() map[string]int {
+			return utils.GetRanks(config, levels, submissions)
+		}
+		model.LoadDetails = func(key string) utils.LevelDetails {
+			return utils.GetLevelDetails(config, levels[key])
 		}
-		p := tea.NewProgram(model)
 
-		if err := p.Start(); err != nil {
+		if err := runProgram(model); err != nil {
 			return "", fmt.Errorf("error displaying level list: %v", err)
 		}
 
 		if model.Done {
-			selectedLevelKey := model.Keys[model.Cursor]
-			selectedLevel := model.Levels[selectedLevelKey]
-			args = append(args, selectedLevel.Contract)
-
-		} else {
-			return "", nil
+			return model.Keys[model.Cursor], nil
 		}
-	}
-
-	level := strings.ToLower(args[0])
 
-	// check if level exists
-	if _, ok := levels[level]; !ok {
-		return "", fmt.Errorf("level %s does not exist", level)
+		return "", nil
 	}
 
-	return level, nil
+	// check if level exists
+	return utils.ResolveLevel(levels, args[0])
 }
 
 func getLang(lang string) (string, error) {
@@ -155,20 +149,17 @@ func getLang(lang string) (string, error) {
 	return lang, nil
 }
 
-func copyTemplateFile(levelsDir, fileToCopy string) error {
+func copyTemplateFile(levelsDir, solutionsDir, fileToCopy string) error {
 	fmt.Printf("Copying template file '%s' ...\n", fileToCopy)
 
-	// copy level from template/src to src
-	src := filepath.Join(levelsDir, "template", fileToCopy)
-	dstSource := filepath.Join(levelsDir, "src", fileToCopy)
+	// copy level from template/ to the solutions directory
+	_, err := utils.CopyTemplate(levelsDir, solutionsDir, fileToCopy, false)
 
-	// Check if file already exists. If yes, ask if overwrite is wanted
-	_, err := os.Stat(dstSource)
-	if !os.IsNotExist(err) {
-		fmt.Printf("File already exists in '%s/src/'.\nOverwrite? (y/n): ", levelsDir)
+	// If the file already exists, ask if overwrite is wanted
+	if errors.Is(err, utils.ErrSolutionExists) {
+		fmt.Printf("File already exists in '%s'.\nOverwrite? (y/n): ", solutionsDir)
 		var overwrite string
-		_, err := fmt.Scanln(&overwrite)
-		if err != nil {
+		if _, err := fmt.Scanln(&overwrite); err != nil {
 			return fmt.Errorf("error reading input: %w", err)
 		}
 
@@ -176,25 +167,15 @@ func copyTemplateFile(levelsDir, fileToCopy string) error {
 			fmt.Printf("Not overwriting '%s'\n\n", fileToCopy)
 			return nil
 		}
-	}
 
-	if err := copyFile(src, dstSource); err != nil {
-		return fmt.Errorf("error copying file: %v", err)
+		_, err = utils.CopyTemplate(levelsDir, solutionsDir, fileToCopy, true)
 	}
-
-	fmt.Printf("Template file copied successfully!\n\n")
-
-	return nil
-}
-
-func copyFile(src, dst string) error {
-	input, err := os.ReadFile(src)
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(dst, input, 0644); err != nil {
-		return err
-	}
+
+	fmt.Printf("Template file copied successfully!\n\n")
+
 	return nil
 }
 
@@ -1,14 +1,11 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/ethernautdao/evm-runners-cli/internal/utils"
 	"github.com/spf13/cobra"
-	"net/http"
 	"os"
-	"strconv"
 	"strings"
 )
 
@@ -25,6 +22,7 @@ score may slightly vary from your local score, particularly for solutions involv
 	RunE: func(cmd *cobra.Command, args []string) error {
 		bytecode, _ := cmd.Flags().GetString("bytecode")
 		lang, _ := cmd.Flags().GetString("lang")
+		compilerArgs, _ := cmd.Flags().GetString("compiler-args")
 
 		// load config
 		config, err := utils.LoadConfig()
@@ -33,14 +31,16 @@ score may slightly vary from your local score, particularly for solutions involv
 		}
 
 		// check if user authenticated
+		if err := utils.LoadCredentials(&config); err != nil {
+			return err
+		}
 		if config.EVMR_TOKEN == "" {
-			return fmt.Errorf("Please authorize first with 'evmr auth discord'\n")
+			return fmt.Errorf("Please authorize first with 'evmr auth'\n")
 		}
 
 		if len(args) == 0 {
 			return fmt.Errorf("Please provide a level\n")
 		}
-		level := strings.ToLower(args[0])
 
 		// get level information
 		levels, err := utils.LoadLevels()
@@ -49,20 +49,36 @@ score may slightly vary from your local score, particularly for solutions involv
 		}
 
 		// check if level exists
-		if _, ok := levels[level]; !ok {
-			return fmt.Errorf("Invalid level: %v\n", level)
+		level, err := utils.ResolveLevel(levels, args[0])
+		if err != nil {
+			return err
+		}
+
+		// use the levels directory and server of the level's pack
+		config, err = utils.PackConfig(config, levels[level].Pack)
+		if err != nil {
+			return err
 		}
 
 		// get filename of level
 		filename := levels[level].File
 
-		bytecode, solutionType, err := utils.GetBytecodeToValidate(bytecode, level, filename, config.EVMR_LEVELS_DIR, lang)
+		solutionsDir, err := utils.SolutionsDir(config)
 		if err != nil {
 			return err
 		}
 
+		bytecode, solutionType, compilerFlags, err := utils.GetBytecodeToValidate(bytecode, level, filename, config.EVMR_LEVELS_DIR, solutionsDir, lang, compilerArgs)
+		if err != nil {
+			return err
+		}
+
+		if len(compilerFlags) > 0 {
+			fmt.Printf("Compiler flags: %s\n\n", strings.Join(compilerFlags, " "))
+		}
+
 		// Check if solution is correct
-		fmt.Printf("Validating solution for level '%s' ...\n", level)
+		fmt.Printf("Validating solution for level '%s' ...\n", utils.LevelName(level))
 
 		os.Setenv("BYTECODE", bytecode)
 
@@ -70,7 +86,7 @@ score may slightly vary from your local score, particularly for solutions involv
 		testContract := levels[level].Contract + "TestBase"
 		output, err := utils.RunTest(config.EVMR_LEVELS_DIR, testContract, false)
 		if err != nil {
-			fmt.Printf("Solution is not correct!\nRun 'evmr validate %s' to inspect it.\n", level)
+			fmt.Printf("Solution is not correct!\nRun 'evmr validate %s' to inspect it.\n", utils.LevelName(level))
 			return nil
 		}
 
@@ -83,99 +99,33 @@ score may slightly vary from your local score, particularly for solutions involv
 		fmt.Printf("Solution is correct! Gas: %d, Size: %d\nNote: The final score can be slightly different.\n", gasValue, sizeValue)
 
 		// Fetch existing submission data
-		submissions, err := utils.FetchSubmissionData(config)
+		existingGas, existingSize, err := utils.SubmittedScores(&config, levels[level])
 		if err != nil {
 			return err
 		}
 
-		if len(submissions) > 0 {
-			// Compare new solution's gas and size with existing submission
-			var existingGas int
-			var existingSize int
-
-			for _, item := range submissions {
-				if level == strings.ToLower(item.LevelName) {
-					existingGas, _ = strconv.Atoi(item.Gas)
-					existingSize, _ = strconv.Atoi(item.Size)
-				}
-			}
-
-			// if existing solution is found (gas and size > 0)
-			if existingGas > 0 && existingSize > 0 {
-				// If gas and size score is worse than existing one, skip submission
-				if gasValue >= existingGas && sizeValue >= existingSize {
-					fmt.Printf("\nWarning: Submission skipped!\nExisting solution is better than the current one (gas: %d, size: %d).\n", existingGas, existingSize)
-					return nil
-				}
-			}
-		}
-
-		// Create a JSON payload
-		payload := map[string]string{
-			"bytecode": bytecode,
-			"type":     solutionType,
-			"user_id":  config.EVMR_ID,
-			"level_id": levels[level].ID,
-		}
-		jsonPayload, _ := json.Marshal(payload)
-
-		// Make the HTTP request
-		url := config.EVMR_SERVER + "submissions"
-		req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+config.EVMR_TOKEN)
-
-		// Send the request
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			return fmt.Errorf("error sending the request: %v", err)
-		}
-		defer resp.Body.Close()
-
-		// Check for errors in the response
-		if resp.StatusCode != http.StatusOK {
-			// if status code is 400, the solution is not correct
-			if resp.StatusCode == http.StatusBadRequest {
-				fmt.Printf("\nBackend tests failed!\nTry submitting again or run 'evmr validate %s' to inspect your solution.\n", level)
+		// if existing solution is found (gas and size > 0)
+		if existingGas > 0 && existingSize > 0 {
+			// If gas and size score is worse than existing one, skip submission
+			if gasValue >= existingGas && sizeValue >= existingSize {
+				fmt.Printf("\nWarning: Submission skipped!\nExisting solution is better than the current one (gas: %d, size: %d).\n", existingGas, existingSize)
 				return nil
 			}
-
-			return fmt.Errorf("http request failed with status: %s", resp.Status)
 		}
 
-		// Decode the JSON response as an array of objects
-		var response []map[string]interface{}
-		dec := json.NewDecoder(resp.Body)
-		if err := dec.Decode(&response); err != nil {
-			return fmt.Errorf("error decoding response: %v", err)
+		result, err := utils.Submit(&config, levels[level], bytecode, solutionType)
+		if errors.Is(err, utils.ErrBackendTestsFailed) {
+			fmt.Printf("\nBackend tests failed!\nTry submitting again or run 'evmr validate %s' to inspect your solution.\n", utils.LevelName(level))
+			return nil
 		}
-
-		// Extract the gas and size rank from the first object in the array
-		gasRank, _ := response[0]["gas_rank"].(string)
-		sizeRank, _ := response[0]["size_rank"].(string)
-
-		// Fetch updated submission data
-		submissions, err = utils.FetchSubmissionData(config)
 		if err != nil {
 			return err
 		}
 
-		var gasScore int
-		var sizeScore int
-		if len(submissions) > 0 {
-			for _, item := range submissions {
-				if level == strings.ToLower(item.LevelName) {
-					gasScore, _ = strconv.Atoi(item.Gas)
-					sizeScore, _ = strconv.Atoi(item.Size)
-				}
-			}
-		}
-
-		fmt.Printf("\nSolution for level '%s' submitted successfully!\n\n", level)
-		fmt.Printf("Size leaderboard: #%s (%d)\nGas leaderboard: #%s (%d)\n", sizeRank, sizeScore, gasRank, gasScore)
+		fmt.Printf("\nSolution for level '%s' submitted successfully!\n\n", utils.LevelName(level))
+		fmt.Printf("Size leaderboard: #%s (%d)\nGas leaderboard: #%s (%d)\n", result.SizeRank, result.Size, result.GasRank, result.Gas)
 
-		fmt.Printf("\nRun 'evmr leaderboard %s' to see the full leaderboard.\n", level)
+		fmt.Printf("\nRun 'evmr leaderboard %s' to see the full leaderboard.\n", utils.LevelName(level))
 
 		return nil
 	},
@@ -187,4 +137,5 @@ func init() {
 	// Flags
 	submitCmd.Flags().StringP("bytecode", "b", "", "The bytecode of the solution")
 	submitCmd.Flags().StringP("lang", "l", "", "The language of the solution file (sol, yul, vyper, huff)")
+	submitCmd.Flags().String("compiler-args", "", "Additional arguments passed to the compiler, e.g. \"--optimizer-runs 1000\"")
 }
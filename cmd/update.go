@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"os"
-	"os/exec"
+	"strings"
 )
 
 // updateCmd represents the update command
@@ -15,10 +15,24 @@ var updateCmd = &cobra.Command{
 	Short: "Update the local evm-runners directory by pulling from Git",
 	Long: `Update the local evm-runners directory by pulling from Git.
 
-Note that this command does not update the evm-runners-cli binary, for that you need to 
+If files of the levels directory were modified locally, you are asked how to handle them:
+  stash  copy the modified files to the evm-runners data directory and update them
+  skip   keep your versions of the modified files and update everything else
+  abort  don't update
+
+Use '--modified' to choose without being asked. When not running in a terminal, the
+update is aborted unless '--modified' is set. Modified files the update doesn't touch
+are always kept.
+
+Note that this command does not update the evm-runners-cli binary, for that you need to
 run 'evmrup'.`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
+		modified, _ := cmd.Flags().GetString("modified")
+		if modified != "" && modified != utils.ModifiedStash && modified != utils.ModifiedSkip && modified != utils.ModifiedAbort {
+			return fmt.Errorf("invalid value '%s' for --modified, use stash, skip or abort\n", modified)
+		}
+
 		// load config
 		config, err := utils.LoadConfig()
 		if err != nil {
@@ -28,21 +42,115 @@ run 'evmrup'.`,
 		fmt.Printf("Updating evm-runners levels...\n\n")
 		if _, err := os.Stat(config.EVMR_LEVELS_DIR); os.IsNotExist(err) {
 			return fmt.Errorf("evm-runners directory not found, run 'evmr init' first!\n")
-		} else {
-			execCmd := exec.Command("git", "pull")
-			execCmd.Stdout = os.Stdout
-			execCmd.Stderr = os.Stderr
-			execCmd.Dir = config.EVMR_LEVELS_DIR
-			if err := execCmd.Run(); err != nil {
-				return fmt.Errorf("error updating the level directory: %v", err)
-			}
 		}
+
+		if err := updateLevels(config, utils.OfficialPack, modified); err != nil {
+			return err
+		}
+
 		fmt.Println("\nDone!")
 
 		return nil
 	},
 }
 
+// updates the levels directory of the config, which belongs to a level pack, asking how to handle local modifications unless modified is set
+func updateLevels(config utils.Config, pack string, modified string) error {
+	// move solutions out of the levels directory before updating it
+	message, err := utils.MigrateSolutions(config)
+	if err != nil {
+		return err
+	}
+	if message != "" {
+		fmt.Println(message)
+	}
+
+	update, err := utils.FetchLevelsUpdate(config.EVMR_LEVELS_DIR, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	if update == nil {
+		fmt.Println("Already up to date.")
+		return nil
+	}
+
+	fmt.Printf("%d new commit(s), %d file(s) changed\n", update.Commits, len(update.Changed))
+
+	mode := utils.ModifiedStash
+	if len(update.Modified) > 0 {
+		fmt.Printf("\nThe following files were modified locally:\n")
+		for _, path := range update.Modified {
+			if update.Changed[path] {
+				fmt.Printf("  %s %s\n", path, utils.ActiveTheme().Warn("(changed by the update)"))
+			} else {
+				fmt.Printf("  %s\n", path)
+			}
+		}
+		fmt.Println()
+
+		mode = modified
+		if mode == "" {
+			if !isInteractive() {
+				return fmt.Errorf("local modifications found, rerun with '--modified stash' or '--modified skip'\n")
+			}
+			if mode, err = promptModified(); err != nil {
+				return err
+			}
+		}
+
+		if mode == utils.ModifiedAbort {
+			fmt.Println("Update aborted, nothing was changed.")
+			return nil
+		}
+	}
+
+	backup, err := utils.BackupSources(config.EVMR_LEVELS_DIR, pack)
+	if err != nil {
+		return err
+	}
+	if backup != "" {
+		fmt.Printf("Backed up src/ to '%s'\n", backup)
+	}
+
+	stash, conflicts, err := update.Apply(mode)
+	if err != nil {
+		return fmt.Errorf("error updating the level directory: %v", err)
+	}
+
+	if stash != "" {
+		fmt.Printf("Your modified files were copied to '%s'\n", stash)
+	}
+	if len(conflicts) > 0 && mode == utils.ModifiedSkip {
+		fmt.Printf("Kept your versions of %d file(s) changed by the update: %s\n", len(conflicts), strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
+
+// asks how to handle local modifications
+func promptModified() (string, error) {
+	for {
+		fmt.Printf("(s)tash your changes and update, s(k)ip the modified files, or (a)bort? ")
+
+		var answer string
+		if _, err := fmt.Scanln(&answer); err != nil {
+			return "", fmt.Errorf("error reading input: %v", err)
+		}
+
+		switch strings.ToLower(answer) {
+		case "s", "stash":
+			return utils.ModifiedStash, nil
+		case "k", "skip":
+			return utils.ModifiedSkip, nil
+		case "a", "abort":
+			return utils.ModifiedAbort, nil
+		}
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(updateCmd)
+
+	updateCmd.Flags().String("modified", "", "How to handle locally modified files: stash, skip or abort")
 }
@@ -22,6 +22,7 @@ score is derived from the µ value in the 'test<level_id>_gas' fuzz test.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		bytecode, _ := cmd.Flags().GetString("bytecode")
 		lang, _ := cmd.Flags().GetString("lang")
+		compilerArgs, _ := cmd.Flags().GetString("compiler-args")
 		verbose, _ := cmd.Flags().GetBool("verbose")
 
 		// load config
@@ -33,7 +34,6 @@ score is derived from the µ value in the 'test<level_id>_gas' fuzz test.`,
 		if len(args) == 0 {
 			return fmt.Errorf("Please provide a level\n")
 		}
-		level := strings.ToLower(args[0])
 
 		// get level information
 		levels, err := utils.LoadLevels()
@@ -42,8 +42,15 @@ score is derived from the µ value in the 'test<level_id>_gas' fuzz test.`,
 		}
 
 		// check if level exists
-		if _, ok := levels[level]; !ok {
-			return fmt.Errorf("Invalid level: %v\n", level)
+		level, err := utils.ResolveLevel(levels, args[0])
+		if err != nil {
+			return err
+		}
+
+		// use the levels directory and server of the level's pack
+		config, err = utils.PackConfig(config, levels[level].Pack)
+		if err != nil {
+			return err
 		}
 
 		// Validating solution ...
@@ -52,11 +59,20 @@ score is derived from the µ value in the 'test<level_id>_gas' fuzz test.`,
 		// get filename and test contract of level
 		filename := levels[level].File
 
-		bytecode, solutionType, err := utils.GetBytecodeToValidate(bytecode, level, filename, config.EVMR_LEVELS_DIR, lang)
+		solutionsDir, err := utils.SolutionsDir(config)
 		if err != nil {
 			return err
 		}
 
+		bytecode, solutionType, compilerFlags, err := utils.GetBytecodeToValidate(bytecode, level, filename, config.EVMR_LEVELS_DIR, solutionsDir, lang, compilerArgs)
+		if err != nil {
+			return err
+		}
+
+		if len(compilerFlags) > 0 {
+			fmt.Printf("Compiler flags: %s\n\n", strings.Join(compilerFlags, " "))
+		}
+
 		os.Setenv("BYTECODE", bytecode)
 
 		// Run test
@@ -85,7 +101,7 @@ score is derived from the µ value in the 'test<level_id>_gas' fuzz test.`,
 
 				fmt.Printf("\nTo test the solution with forge, run 'forge test --mc %s -vvvv' in '%s'\n", userTestContract, config.EVMR_LEVELS_DIR)
 			} else {
-				fmt.Printf("\nTo see the stack traces of the failed tests, run 'evmr validate %s -l %s -v'\n", level, lang)
+				fmt.Printf("\nTo see the stack traces of the failed tests, run 'evmr validate %s -l %s -v'\n", utils.LevelName(level), lang)
 			}
 
 			return nil
@@ -104,9 +120,9 @@ score is derived from the µ value in the 'test<level_id>_gas' fuzz test.`,
 		fmt.Printf("Solution is correct! Gas: %d, Size: %d\n", gasValue, sizeValue)
 
 		if lang != "" {
-			fmt.Printf("To submit it, run 'evmr submit %s -l %s'\n", level, lang)
+			fmt.Printf("To submit it, run 'evmr submit %s -l %s'\n", utils.LevelName(level), lang)
 		} else {
-			fmt.Printf("To submit it, run 'evmr submit %s'\n", level)
+			fmt.Printf("To submit it, run 'evmr submit %s'\n", utils.LevelName(level))
 		}
 
 		return nil
@@ -119,4 +135,5 @@ func init() {
 	validateCmd.Flags().StringP("bytecode", "b", "", "The creation bytecode to submit")
 	validateCmd.Flags().StringP("lang", "l", "", "The language of the solution file (sol, yul, vyper, huff)")
 	validateCmd.Flags().BoolP("verbose", "v", false, "Verbose output, shows stack traces of all tests")
+	validateCmd.Flags().String("compiler-args", "", "Additional arguments passed to the compiler, e.g. \"--optimizer-runs 1000\"")
 }
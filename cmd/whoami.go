@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethernautdao/evm-runners-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// whoamiCmd represents the whoami command
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the account you are authenticated as",
+	Long: `Show the account you are authenticated as.
+
+Your auth token is verified with the server, and your user ID, name,
+linked Optimism address and the expiry of your auth token are printed.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := utils.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		user, err := utils.FetchUserInfo(&config)
+		if errors.Is(err, utils.ErrNotSupported) {
+			// older servers have no user endpoint, so the token is verified by fetching the user's submissions
+			if _, err := utils.FetchSubmissionData(&config); err != nil {
+				return err
+			}
+			user = utils.UserInfo{ID: config.EVMR_ID, Name: config.EVMR_NAME}
+		} else if err != nil {
+			return err
+		}
+
+		address := user.Address
+		if address == "" {
+			address = "not linked, run 'evmr address' to link it"
+		}
+
+		expiry := "unknown"
+		expiresAt := user.ExpiresAt
+		if expiresAt.IsZero() {
+			expiresAt, _ = utils.TokenExpiry(config.EVMR_TOKEN)
+		}
+		if !expiresAt.IsZero() {
+			expiry = fmt.Sprintf("%s (%s)", expiresAt.Local().Format("2006-01-02 15:04"), formatExpiry(time.Until(expiresAt)))
+		}
+
+		fmt.Printf("Logged in as '%s'\n\n", user.Name)
+		fmt.Printf("%-20s%s\n", "User ID:", user.ID)
+		fmt.Printf("%-20s%s\n", "Name:", user.Name)
+		fmt.Printf("%-20s%s\n", "Optimism address:", address)
+		fmt.Printf("%-20s%s\n", "Token expires:", expiry)
+		fmt.Printf("%-20s%s\n", "Server:", config.EVMR_SERVER)
+
+		return nil
+	},
+}
+
+// formats the time until a token expires, e.g. "in 3 days"
+func formatExpiry(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "expired"
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("in %d days", int(d.Hours()/24))
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("in %d hours", int(d.Hours()))
+	default:
+		return fmt.Sprintf("in %d minutes", int(d.Minutes()))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+}
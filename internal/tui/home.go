@@ -0,0 +1,716 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ethernautdao/evm-runners-cli/internal/utils"
+)
+
+// screens of the home screen
+const (
+	screenLevels = iota
+	screenLanguage
+	screenRun
+	screenLeaderboard
+)
+
+// actions on the selected level
+const (
+	actionStart       = "start"
+	actionValidate    = "validate"
+	actionSubmit      = "submit"
+	actionLeaderboard = "leaderboard"
+)
+
+const (
+	// lines of the home screen around the level list: the header and the status line
+	homeChrome = 2
+	// lines of the leaderboard screen that are not submissions
+	homeLeaderboardChrome = 19
+)
+
+// names of the languages shown in the language picker
+var languageNames = map[string]string{"sol": "solidity", "yul": "yul", "vy": "vyper", "huff": "huff"}
+
+// full-screen app shown when evmr is run without a command. Levels are started, validated,
+// submitted and their leaderboards shown without leaving the level list.
+type HomeModel struct {
+	config utils.Config
+	levels map[string]utils.Level
+
+	screen int
+	list   *levelListModel
+	lang   *langListModel
+
+	// level and action the language picker is shown for
+	key    string
+	action string
+
+	// question shown in the status line, answered with y or n
+	confirm   string
+	onConfirm func() tea.Cmd
+
+	// result of the last action, shown in the status line
+	status    string
+	statusErr bool
+
+	run   runOutput
+	board leaderboardView
+
+	// terminal size, 0 if unknown
+	width  int
+	height int
+}
+
+// output of a validation or submission
+type runOutput struct {
+	title    string
+	lines    []string
+	running  bool
+	result   string
+	ok       bool
+	cancel   context.CancelFunc
+	canceled <-chan struct{}
+	msgs     chan tea.Msg
+}
+
+type runLineMsg string
+
+type runDoneMsg struct {
+	result    string
+	ok        bool
+	submitted bool
+}
+
+type leaderboardView struct {
+	key     string
+	loading bool
+	gas     []utils.SubmissionData
+	size    []utils.SubmissionData
+	err     error
+}
+
+type leaderboardsLoadedMsg struct {
+	key  string
+	gas  []utils.SubmissionData
+	size []utils.SubmissionData
+	err  error
+}
+
+func NewHome(config utils.Config, levels map[string]utils.Level, solves map[string]string, submissions map[string]string) *HomeModel {
+	list := NewLevelList(levels, solves, submissions)
+	list.LoadRanks = func() map[string]int {
+		return utils.GetRanks(config, levels, submissions)
+	}
+	list.LoadDetails = func(key string) utils.LevelDetails {
+		return utils.GetLevelDetails(config, levels[key])
+	}
+
+	box := utils.ActiveTheme().Box
+	list.actions = []string{box.Enter + " - Start", "v - Validate", "s - Submit", "l - Leaderboard"}
+
+	m := &HomeModel{config: config, levels: levels, list: list, width: list.width, height: list.height}
+	if m.height > 0 {
+		list.height = maxInt(m.height-homeChrome, 1)
+	}
+
+	return m
+}
+
+func (m *HomeModel) Init() tea.Cmd {
+	return m.list.Init()
+}
+
+func (m *HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.list.Update(tea.WindowSizeMsg{Width: msg.Width, Height: maxInt(msg.Height-homeChrome, 1)})
+		return m, nil
+
+	case runLineMsg:
+		m.run.lines = append(m.run.lines, string(msg))
+		return m, m.waitForRun()
+
+	case runDoneMsg:
+		m.run.running = false
+		m.run.result = msg.result
+		m.run.ok = msg.ok
+		if msg.submitted {
+			m.solved(m.key)
+		}
+		return m, nil
+
+	case leaderboardsLoadedMsg:
+		if msg.key == m.board.key {
+			m.board = leaderboardView{key: msg.key, gas: msg.gas, size: msg.size, err: msg.err}
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		return m, m.updateKey(msg)
+	}
+
+	// e.g. loaded ranks and level details
+	_, cmd := m.list.Update(msg)
+	return m, cmd
+}
+
+func (m *HomeModel) updateKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "ctrl+c" {
+		return m.quit()
+	}
+
+	switch m.screen {
+	case screenLevels:
+		if m.confirm != "" {
+			switch msg.String() {
+			case "y", "Y":
+				onConfirm := m.onConfirm
+				m.confirm = ""
+				return onConfirm()
+			case "n", "N", "esc":
+				m.confirm = ""
+			}
+			return nil
+		}
+
+		// keys are part of the search query while searching
+		if m.list.searching {
+			_, cmd := m.list.Update(msg)
+			return cmd
+		}
+
+		switch msg.String() {
+		case "enter":
+			return m.selectedAction(actionStart)
+		case "v":
+			return m.selectedAction(actionValidate)
+		case "s":
+			return m.selectedAction(actionSubmit)
+		case "l":
+			return m.selectedAction(actionLeaderboard)
+		case "q":
+			return m.quit()
+		}
+
+		_, cmd := m.list.Update(msg)
+		return cmd
+
+	case screenLanguage:
+		switch msg.String() {
+		case "up", "down":
+			m.lang.Update(msg)
+		case "enter":
+			m.screen = screenLevels
+			return m.languageChosen(m.lang.Lang[m.lang.Cursor])
+		case "esc", "left":
+			m.screen = screenLevels
+		case "q":
+			return m.quit()
+		}
+
+	case screenRun:
+		if m.run.running {
+			switch msg.String() {
+			case "esc":
+				m.run.cancel()
+				m.run.running = false
+				m.run.result = "Canceled."
+			case "q":
+				return m.quit()
+			}
+			return nil
+		}
+
+		switch msg.String() {
+		case "v":
+			return m.runAction(m.key, actionValidate)
+		case "s":
+			return m.runAction(m.key, actionSubmit)
+		case "l":
+			return m.runAction(m.key, actionLeaderboard)
+		case "esc", "left", "enter":
+			m.screen = screenLevels
+		case "q":
+			return m.quit()
+		}
+
+	case screenLeaderboard:
+		switch msg.String() {
+		case "esc", "left", "enter", "l":
+			m.screen = screenLevels
+		case "q":
+			return m.quit()
+		}
+	}
+
+	return nil
+}
+
+func (m *HomeModel) quit() tea.Cmd {
+	if m.run.running {
+		m.run.cancel()
+	}
+	return tea.Quit
+}
+
+// runs an action on the level selected in the level list
+func (m *HomeModel) selectedAction(action string) tea.Cmd {
+	if len(m.list.Keys) == 0 {
+		return nil
+	}
+	return m.runAction(m.list.Keys[m.list.Cursor], action)
+}
+
+// starts, validates or submits a level or shows its leaderboards
+func (m *HomeModel) runAction(key string, action string) tea.Cmd {
+	level := m.levels[key]
+	m.key = key
+	m.status = ""
+	m.screen = screenLevels
+
+	// use the levels directory and server of the level's pack
+	config, err := utils.PackConfig(m.config, level.Pack)
+	if err != nil {
+		m.fail(err.Error())
+		return nil
+	}
+
+	switch action {
+	case actionStart:
+		var langs []string
+		for _, lang := range []string{"sol", "yul", "vy", "huff"} {
+			if level.SupportsLanguage(lang) {
+				langs = append(langs, lang)
+			}
+		}
+		m.chooseLanguage(action, fmt.Sprintf("Which template do you want to use for '%s'?", utils.LevelName(key)), append(langs, "no template"))
+
+	case actionValidate, actionSubmit:
+		if action == actionSubmit && config.EVMR_TOKEN == "" {
+			m.fail("Please authorize first with 'evmr auth'")
+			return nil
+		}
+
+		solutionsDir, err := utils.SolutionsDir(config)
+		if err != nil {
+			m.fail(err.Error())
+			return nil
+		}
+
+		types := utils.SolutionTypes(solutionsDir, level.File)
+		switch len(types) {
+		case 0:
+			m.fail(fmt.Sprintf("No solution file found for '%s' in '%s'. Press %s to start solving it.", utils.LevelName(key), solutionsDir, utils.ActiveTheme().Box.Enter))
+		case 1:
+			return m.solutionChosen(action, types[0])
+		default:
+			m.chooseLanguage(action, fmt.Sprintf("Which solution do you want to %s?", action), types)
+		}
+
+	case actionLeaderboard:
+		m.screen = screenLeaderboard
+		m.board = leaderboardView{key: key, loading: true}
+		return fetchLeaderboards(config.EVMR_SERVER, key, level.ID)
+	}
+
+	return nil
+}
+
+// shows the language picker for an action on the selected level
+func (m *HomeModel) chooseLanguage(action string, question string, langs []string) {
+	m.action = action
+	m.lang = &langListModel{Lang: langs, question: question}
+	for _, lang := range langs {
+		name, ok := languageNames[lang]
+		if !ok {
+			name = lang
+		}
+		m.lang.Options = append(m.lang.Options, name)
+	}
+	m.screen = screenLanguage
+}
+
+func (m *HomeModel) languageChosen(lang string) tea.Cmd {
+	if m.action == actionStart {
+		m.copyTemplate(lang, false)
+		return nil
+	}
+	return m.solutionChosen(m.action, lang)
+}
+
+// copies the template of the selected level, asking before a solution file is overwritten
+func (m *HomeModel) copyTemplate(lang string, overwrite bool) {
+	level := m.levels[m.key]
+
+	config, err := utils.PackConfig(m.config, level.Pack)
+	if err != nil {
+		m.fail(err.Error())
+		return
+	}
+
+	solutionsDir, err := utils.SolutionsDir(config)
+	if err != nil {
+		m.fail(err.Error())
+		return
+	}
+
+	if lang == "no template" {
+		m.succeed(fmt.Sprintf("No template file selected. You can start working on '%s' in '%s'.", utils.LevelName(m.key), solutionsDir))
+		return
+	}
+
+	path, err := utils.CopyTemplate(config.EVMR_LEVELS_DIR, solutionsDir, level.File+"."+lang, overwrite)
+	if errors.Is(err, utils.ErrSolutionExists) {
+		m.ask(fmt.Sprintf("'%s' already exists. Overwrite? (y/n)", path), func() tea.Cmd {
+			m.copyTemplate(lang, true)
+			return nil
+		})
+		return
+	}
+	if err != nil {
+		m.fail(err.Error())
+		return
+	}
+
+	m.succeed(fmt.Sprintf("Template copied to '%s'. Press v to validate your solution.", path))
+}
+
+func (m *HomeModel) solutionChosen(action string, lang string) tea.Cmd {
+	if action == actionSubmit {
+		m.ask(fmt.Sprintf("Submit your %s solution for '%s'? (y/n)", languageNames[lang], utils.LevelName(m.key)), func() tea.Cmd {
+			return m.startRun(true, lang)
+		})
+		return nil
+	}
+
+	return m.startRun(false, lang)
+}
+
+// validates the solution of the selected level, and submits it if submit is set, showing the output of the tests while they run
+func (m *HomeModel) startRun(submit bool, lang string) tea.Cmd {
+	level := m.levels[m.key]
+
+	config, err := utils.PackConfig(m.config, level.Pack)
+	if err != nil {
+		m.fail(err.Error())
+		return nil
+	}
+
+	title := fmt.Sprintf("Validating your %s solution for '%s'", languageNames[lang], utils.LevelName(m.key))
+	if submit {
+		title = fmt.Sprintf("Submitting your %s solution for '%s'", languageNames[lang], utils.LevelName(m.key))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.run = runOutput{title: title, running: true, cancel: cancel, canceled: ctx.Done(), msgs: make(chan tea.Msg)}
+	m.screen = screenRun
+
+	go runSolution(ctx, config, m.key, level, lang, submit, m.run.msgs)
+
+	return m.waitForRun()
+}
+
+// returns the command receiving the next line of output or the result of the run
+func (m *HomeModel) waitForRun() tea.Cmd {
+	msgs, canceled := m.run.msgs, m.run.canceled
+	return func() tea.Msg {
+		select {
+		case msg := <-msgs:
+			// drop output of a run that was canceled in the meantime
+			select {
+			case <-canceled:
+				return nil
+			default:
+				return msg
+			}
+		case <-canceled:
+			return nil
+		}
+	}
+}
+
+// compiles and tests a solution like 'evmr validate' and 'evmr submit', sending the output to msgs and a runDoneMsg when done.
+// Nothing is sent anymore after ctx is canceled.
+func runSolution(ctx context.Context, config utils.Config, key string, level utils.Level, lang string, submit bool, msgs chan<- tea.Msg) {
+	send := func(msg tea.Msg) {
+		select {
+		case msgs <- msg:
+		case <-ctx.Done():
+		}
+	}
+	done := func(result string, ok bool, submitted bool) {
+		send(runDoneMsg{result: result, ok: ok, submitted: submitted})
+	}
+
+	solutionsDir, err := utils.SolutionsDir(config)
+	if err != nil {
+		done(errorText(err), false, false)
+		return
+	}
+
+	send(runLineMsg("Compiling..."))
+
+	bytecode, solutionType, compilerFlags, err := utils.GetBytecodeToValidate("", key, level.File, config.EVMR_LEVELS_DIR, solutionsDir, lang, "")
+	if err != nil {
+		// compiler errors span multiple lines
+		for _, line := range strings.Split(strings.TrimSpace(err.Error()), "\n") {
+			send(runLineMsg(line))
+		}
+		done("Compiling the solution failed.", false, false)
+		return
+	}
+
+	if len(compilerFlags) > 0 {
+		send(runLineMsg("Compiler flags: " + strings.Join(compilerFlags, " ")))
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	os.Setenv("BYTECODE", bytecode)
+
+	// forward the output of the tests while they run
+	lines := make(chan string)
+	finished := make(chan struct{})
+	var output []byte
+	var testErr error
+	go func() {
+		output, testErr = utils.StreamTest(ctx, config.EVMR_LEVELS_DIR, level.Contract+"TestBase", false, lines)
+		close(finished)
+	}()
+
+	for line := range lines {
+		send(runLineMsg(line))
+	}
+	<-finished
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	if testErr != nil {
+		done(fmt.Sprintf("Solution is not correct! Run 'evmr validate %s -l %s -v' to see the stack traces of the failed tests.", utils.LevelName(key), lang), false, false)
+		return
+	}
+
+	gasValue, sizeValue, err := utils.ParseOutput(string(output))
+	if err != nil {
+		done(errorText(err), false, false)
+		return
+	}
+
+	if !submit {
+		done(fmt.Sprintf("Solution is correct! Gas: %d, Size: %d. Press s to submit it.", gasValue, sizeValue), true, false)
+		return
+	}
+
+	send(runLineMsg(""))
+	send(runLineMsg(fmt.Sprintf("Solution is correct! Gas: %d, Size: %d. Submitting...", gasValue, sizeValue)))
+
+	existingGas, existingSize, err := utils.SubmittedScores(&config, level)
+	if err != nil {
+		done(errorText(err), false, false)
+		return
+	}
+
+	// If gas and size score is worse than existing one, skip submission
+	if existingGas > 0 && existingSize > 0 && gasValue >= existingGas && sizeValue >= existingSize {
+		done(fmt.Sprintf("Submission skipped! Your existing solution is better than the current one (gas: %d, size: %d).", existingGas, existingSize), false, false)
+		return
+	}
+
+	result, err := utils.Submit(&config, level, bytecode, solutionType)
+	if errors.Is(err, utils.ErrBackendTestsFailed) {
+		done("Backend tests failed! Try submitting again or validate your solution to inspect it.", false, false)
+		return
+	}
+	if err != nil {
+		done(errorText(err), false, false)
+		return
+	}
+
+	done(fmt.Sprintf("Solution submitted! Gas leaderboard: #%s (%d), size leaderboard: #%s (%d). Press l to see the leaderboards.", result.GasRank, result.Gas, result.SizeRank, result.Size), true, true)
+}
+
+// marks a level as solved in the level list after it was submitted
+func (m *HomeModel) solved(key string) {
+	m.list.submissions[key] = "x"
+	// ranks and details of the level changed
+	m.list.ranks = nil
+	delete(m.list.detail.details, key)
+	if m.list.sortBy == sortByRank {
+		m.list.sortBy = sortByID
+	}
+	m.list.applyFilters()
+}
+
+func fetchLeaderboards(server string, key string, levelId string) tea.Cmd {
+	return func() tea.Msg {
+		gas, err := utils.FetchLeaderboard(server, "gas", levelId)
+		if err != nil {
+			return leaderboardsLoadedMsg{key: key, err: fmt.Errorf("error fetching gas leaderboard data: %v", err)}
+		}
+
+		size, err := utils.FetchLeaderboard(server, "size", levelId)
+		if err != nil {
+			return leaderboardsLoadedMsg{key: key, err: fmt.Errorf("error fetching size leaderboard data: %v", err)}
+		}
+
+		// Limit the leaderboards to the top 10
+		return leaderboardsLoadedMsg{key: key, gas: gas[:minInt(len(gas), 10)], size: size[:minInt(len(size), 10)]}
+	}
+}
+
+func (m *HomeModel) ask(question string, onConfirm func() tea.Cmd) {
+	m.confirm = question
+	m.onConfirm = onConfirm
+}
+
+func (m *HomeModel) succeed(status string) {
+	m.status = status
+	m.statusErr = false
+}
+
+func (m *HomeModel) fail(status string) {
+	m.status = strings.ReplaceAll(strings.TrimSpace(status), "\n", " ")
+	m.statusErr = true
+}
+
+// returns the message of an error on a single line
+func errorText(err error) string {
+	return strings.ReplaceAll(strings.TrimSpace(err.Error()), "\n", " ")
+}
+
+func (m *HomeModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString(m.header() + "\n")
+
+	switch m.screen {
+	case screenLevels:
+		sb.WriteString(m.list.View())
+		sb.WriteString("\n" + m.statusLine())
+	case screenLanguage:
+		sb.WriteString("\n" + m.lang.View())
+	case screenRun:
+		sb.WriteString(m.runView())
+	case screenLeaderboard:
+		sb.WriteString(m.leaderboardView())
+	}
+
+	return sb.String()
+}
+
+func (m *HomeModel) header() string {
+	theme := utils.ActiveTheme()
+
+	user := "not authenticated, run 'evmr auth' to submit solutions"
+	if m.config.EVMR_TOKEN != "" && m.config.EVMR_NAME != "" {
+		user = "logged in as " + m.config.EVMR_NAME
+	} else if m.config.EVMR_TOKEN != "" {
+		user = "logged in"
+	}
+
+	return m.truncate(theme.Bold("evm-runners") + "  " + theme.Muted(user))
+}
+
+// returns the question or the result of the last action
+func (m *HomeModel) statusLine() string {
+	theme := utils.ActiveTheme()
+
+	switch {
+	case m.confirm != "":
+		return m.truncate(theme.Warn(m.confirm))
+	case m.status != "" && m.statusErr:
+		return m.truncate(theme.Fail(m.status))
+	case m.status != "":
+		return m.truncate(theme.Pass(m.status))
+	}
+
+	return ""
+}
+
+func (m *HomeModel) runView() string {
+	var sb strings.Builder
+	theme := utils.ActiveTheme()
+
+	sb.WriteString("\n" + theme.Bold(m.run.title) + "\n\n")
+
+	var help []string
+	if m.run.running {
+		help = []string{"esc - Cancel", "q to exit"}
+	} else {
+		help = []string{"v - Validate", "s - Submit", "l - Leaderboard", theme.Box.Left + " - Back", "q to exit"}
+	}
+	helpText := joinWrapped(help, " | ", m.width)
+
+	result := "Running..."
+	if !m.run.running {
+		result = joinWrapped(strings.Fields(m.run.result), " ", m.width)
+	}
+
+	// show the latest output that fits the terminal
+	lines := m.run.lines
+	if m.height > 0 {
+		page := maxInt(m.height-strings.Count(helpText+result, "\n")-8, 1)
+		lines = lines[maxInt(len(lines)-page, 0):]
+	}
+	for _, line := range lines {
+		sb.WriteString(m.truncate(line) + "\n")
+	}
+
+	switch {
+	case m.run.running:
+		result = theme.Muted(result)
+	case m.run.ok:
+		result = theme.Pass(result)
+	default:
+		result = theme.Fail(result)
+	}
+
+	sb.WriteString("\n" + result + "\n\n")
+	sb.WriteString(theme.Muted(helpText))
+
+	return sb.String()
+}
+
+func (m *HomeModel) leaderboardView() string {
+	var sb strings.Builder
+	theme := utils.ActiveTheme()
+
+	sb.WriteString("\n" + theme.Bold(fmt.Sprintf("Leaderboards of '%s'", utils.LevelName(m.board.key))) + "\n\n")
+
+	switch {
+	case m.board.loading:
+		sb.WriteString("Loading...\n")
+	case m.board.err != nil:
+		sb.WriteString(theme.Fail(errorText(m.board.err)) + "\n")
+	default:
+		rows := 0
+		if m.height > 0 {
+			rows = maxInt((m.height-homeLeaderboardChrome)/2, 1)
+		}
+		sb.WriteString(leaderboardTable(m.board.gas, "gas", m.width, rows) + "\n")
+		sb.WriteString(leaderboardTable(m.board.size, "size", m.width, rows))
+	}
+
+	sb.WriteString("\n" + theme.Muted(joinWrapped([]string{theme.Box.Left + " - Back", "q to exit"}, " | ", m.width)))
+
+	return sb.String()
+}
+
+// shortens a line to the terminal width
+func (m *HomeModel) truncate(s string) string {
+	if m.width <= 0 {
+		return s
+	}
+	return strings.TrimRight(fit(s, m.width-1), " ")
+}
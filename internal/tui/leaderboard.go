@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -9,104 +10,101 @@ import (
 	"github.com/ethernautdao/evm-runners-cli/internal/utils"
 )
 
+// lines of the combined leaderboards that are not submissions: headlines, borders, headers and help
+const leaderboardChrome = 18
+
 type LeaderboardUI struct {
-	content string
+	submissions []utils.SubmissionData
+	field       string
+
+	// terminal width and the number of submissions that fit the terminal, 0 if unknown
+	width int
+	rows  int
 }
 
 type CombinedLeaderboardUI struct {
 	GasUI  *LeaderboardUI
 	SizeUI *LeaderboardUI
-}
 
-func NewLeaderboardUI(submissions []utils.SubmissionData, field string) (*LeaderboardUI, error) {
-	// Check terminal size
-	if err := utils.CheckMinTerminalWidth(); err != nil {
-		return nil, err
-	}
+	width  int
+	height int
+}
 
-	ui := &LeaderboardUI{
-		content: leaderboardTable(submissions, field),
-	}
-	return ui, nil
+func NewLeaderboardUI(submissions []utils.SubmissionData, field string) *LeaderboardUI {
+	return &LeaderboardUI{submissions: submissions, field: field}
 }
 
-func leaderboardTable(submissions []utils.SubmissionData, field string) string {
+func leaderboardTable(submissions []utils.SubmissionData, field string, width int, rows int) string {
 	var sb strings.Builder
 
-	tableWidth := 75
-
-	if len(submissions) > 0 {
-
-		var headlineText string
-		if field == "gas" {
-			headlineText = "GAS LEADERBOARD"
-		} else if field == "size" {
-			headlineText = "SIZE LEADERBOARD"
-		}
-
-		headline := "\x1b[1m" + headlineText + "\x1b[0m" + "\n\n"
-		header := fmt.Sprintf("\x1b[90m│\x1b[0m #\t%-22s%-14s%-20s%-12s\x1b[90m│\x1b[0m\n", "USER", strings.ToUpper(field), "DATE", "TYPE")
-		separator := "\x1b[90m" + "│" + strings.Repeat("─", tableWidth) + "│" + "\n" + "\x1b[0m"
-
-		// Calculate padding for the headline
-		headlinePadding := (tableWidth - len(headlineText)) / 2
-		padding := strings.Repeat(" ", headlinePadding)
-		centeredHeadline := padding + headline
-
-		sb.WriteString(centeredHeadline)
-		sb.WriteString("\x1b[90m┌" + strings.Repeat("─", tableWidth) + "┐\n\x1b[0m") // Top border of the box
-		sb.WriteString(header)
-		sb.WriteString(separator)
-	} else {
+	if len(submissions) == 0 {
 		// No submissions, display a message
-		sb.WriteString(fmt.Sprintf("No submissions available for the %s leaderboard!", field))
-		return sb.String()
+		return fmt.Sprintf("No submissions available for the %s leaderboard!", field)
 	}
 
-	dateLayout := "2006-01-02T15:04:05.000Z"
-	displayLayout := "Jan 02 2006"
+	t := newTable([]column{
+		{title: "#", width: 5},
+		{title: "USER", width: 22, minWidth: 10},
+		{title: strings.ToUpper(field), width: 14},
+		{title: "DATE", width: 20, priority: 2},
+		{title: "TYPE", width: 12, priority: 1},
+	}, width)
+
+	var headlineText string
+	if field == "gas" {
+		headlineText = "GAS LEADERBOARD"
+	} else if field == "size" {
+		headlineText = "SIZE LEADERBOARD"
+	}
 
-	for i, submission := range submissions {
-		userStr := fmt.Sprintf("%s", submission.Username)
+	sb.WriteString(center(t.theme.Bold(headlineText), t.width) + "\n\n")
+	sb.WriteString(t.top())
+	sb.WriteString(t.header())
+	sb.WriteString(t.separator())
 
-		// Check if the userStr is longer than 20 characters
-		if len(userStr) > 20 {
-			// Truncate the userStr to 20 characters
-			userStr = userStr[:20]
+	dateLayout := "2006-01-02T15:04:05.000Z"
+	displayLayout := "Jan 02 2006"
 
-			// Replace the last two characters with ".."
-			userStr = userStr[:18] + ".."
-		}
+	if rows > 0 && rows < len(submissions) {
+		submissions = submissions[:rows]
+	}
 
+	for i, submission := range submissions {
 		// Convert the date string to a time.Time object and format it
-		date, err := time.Parse(dateLayout, submission.SubmittedAt)
-		if err != nil {
-			return fmt.Sprintf("Error parsing date: %v", err)
+		dateStr := submission.SubmittedAt
+		if date, err := time.Parse(dateLayout, submission.SubmittedAt); err == nil {
+			dateStr = date.Format(displayLayout)
 		}
-		dateStr := date.Format(displayLayout)
 
-		if field == "gas" {
-			sb.WriteString(fmt.Sprintf("\x1b[90m│\x1b[0m %d\t%-22s%-14s%-20s%-12s\x1b[90m│\x1b[0m\n", i+1, userStr, submission.Gas, dateStr, submission.Type))
-		} else if field == "size" {
-			sb.WriteString(fmt.Sprintf("\x1b[90m│\x1b[0m %d\t%-22s%-14s%-20s%-12s\x1b[90m│\x1b[0m\n", i+1, userStr, submission.Size, dateStr, submission.Type))
+		score := submission.Gas
+		if field == "size" {
+			score = submission.Size
 		}
+
+		sb.WriteString(t.row("  ", []string{strconv.Itoa(i + 1), submission.Username, score, dateStr, submission.Type}))
 	}
 
-	sb.WriteString("\x1b[90m└" + strings.Repeat("─", tableWidth) + "┘\n\x1b[0m") // Bottom border of the box
+	sb.WriteString(t.bottom())
 
 	return sb.String()
 }
 
 func (ui *LeaderboardUI) View() string {
-	return ui.content
+	return leaderboardTable(ui.submissions, ui.field, ui.width, ui.rows)
 }
 
 func (ui *CombinedLeaderboardUI) Init() tea.Cmd {
+	ui.width, ui.height = utils.TerminalSize()
+	ui.resize()
+
 	return tea.EnterAltScreen
 }
 
 func (ui *CombinedLeaderboardUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		ui.width, ui.height = msg.Width, msg.Height
+		ui.resize()
 	case tea.KeyMsg:
 		switch msg.String() {
 		default:
@@ -116,6 +114,19 @@ func (ui *CombinedLeaderboardUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return ui, nil
 }
 
+// fits both leaderboards into the terminal
+func (ui *CombinedLeaderboardUI) resize() {
+	rows := 0
+	if ui.height > 0 {
+		rows = maxInt((ui.height-leaderboardChrome)/2, 1)
+	}
+
+	for _, board := range []*LeaderboardUI{ui.GasUI, ui.SizeUI} {
+		board.width = ui.width
+		board.rows = rows
+	}
+}
+
 func (ui *CombinedLeaderboardUI) View() string {
-	return "\n" + ui.GasUI.View() + "\n\n" + ui.SizeUI.View() + "\x1b[90m" + "\n\nPress any key to exit." + "\x1b[0m"
+	return "\n" + ui.GasUI.View() + "\n\n" + ui.SizeUI.View() + "\n\n" + utils.ActiveTheme().Muted("Press any key to exit.")
 }
@@ -0,0 +1,252 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/ethernautdao/evm-runners-cli/internal/utils"
+	"golang.org/x/term"
+)
+
+// width the level details are wrapped at if the terminal width is unknown, and at most
+const detailWidth = 100
+
+// pane of the level list showing the details of the selected level
+type levelDetailPane struct {
+	shown bool
+	key   string
+	level utils.Level
+	// details by level key, each level is only loaded once
+	details map[string]utils.LevelDetails
+	loading bool
+
+	// terminal width, 0 if unknown
+	terminalWidth int
+	// help of the keys acting on the level, see levelListModel
+	actions []string
+	// rendered lines and the first line that is shown
+	lines  []string
+	offset int
+}
+
+type detailsLoadedMsg struct {
+	key     string
+	details utils.LevelDetails
+}
+
+// shows the pane for a level and returns the command loading its details, if they are not loaded yet
+func (p *levelDetailPane) open(key string, level utils.Level, terminalWidth int, load func(key string) utils.LevelDetails) tea.Cmd {
+	p.shown = true
+	p.key = key
+	p.level = level
+	p.offset = 0
+	p.terminalWidth = terminalWidth
+
+	if p.details == nil {
+		p.details = make(map[string]utils.LevelDetails)
+	}
+
+	_, ok := p.details[key]
+	p.loading = !ok && load != nil
+	p.render()
+
+	if !p.loading {
+		return nil
+	}
+
+	return func() tea.Msg {
+		return detailsLoadedMsg{key: key, details: load(key)}
+	}
+}
+
+func (p *levelDetailPane) loaded(msg detailsLoadedMsg) {
+	p.details[msg.key] = msg.details
+
+	if msg.key == p.key {
+		p.loading = false
+		p.render()
+	}
+}
+
+// rewraps the details after the terminal was resized
+func (p *levelDetailPane) resize(terminalWidth int) {
+	p.terminalWidth = terminalWidth
+	if p.shown {
+		p.render()
+	}
+}
+
+// returns the width details are wrapped at, leaving room for the margins of the markdown
+func detailWrapWidth(terminalWidth int) int {
+	if terminalWidth <= 0 {
+		return detailWidth
+	}
+	return maxInt(minInt(terminalWidth-4, detailWidth), 20)
+}
+
+func (p *levelDetailPane) render() {
+	var details *utils.LevelDetails
+	if d, ok := p.details[p.key]; ok {
+		details = &d
+	} else if !p.loading {
+		details = &utils.LevelDetails{}
+	}
+
+	out, err := RenderMarkdown(LevelDetailMarkdown(p.key, p.level, details, false), detailWrapWidth(p.terminalWidth))
+	if err != nil {
+		out = p.level.Description
+	}
+
+	p.lines = strings.Split(strings.TrimRight(out, "\n"), "\n")
+}
+
+// returns the help below the details, wrapped to the terminal width
+func (p *levelDetailPane) help() string {
+	box := utils.ActiveTheme().Box
+	help := []string{box.Up + "/" + box.Down + " - Scroll", box.Left + " - Back"}
+	help = append(help, levelActions(p.actions)...)
+	return joinWrapped(append(help, "q to exit"), " | ", p.terminalWidth)
+}
+
+// number of lines that fit the terminal, all lines if the height is unknown
+func (p *levelDetailPane) pageSize(height int) int {
+	if height == 0 {
+		return len(p.lines)
+	}
+	return maxInt(height-strings.Count(p.help(), "\n")-3, 3)
+}
+
+func (p *levelDetailPane) scroll(delta int, height int) {
+	p.offset = maxInt(minInt(p.offset+delta, len(p.lines)-p.pageSize(height)), 0)
+}
+
+func (p *levelDetailPane) view(height int) string {
+	var sb strings.Builder
+
+	page := p.pageSize(height)
+	for _, line := range p.lines[p.offset:minInt(p.offset+page, len(p.lines))] {
+		sb.WriteString(line + "\n")
+	}
+
+	sb.WriteString("\n" + utils.ActiveTheme().Muted(p.help()))
+
+	return sb.String()
+}
+
+// returns the details of a level as markdown, details is nil while they are loaded.
+// Hints are only listed if showHints is set, as they spoil the level.
+func LevelDetailMarkdown(key string, level utils.Level, details *utils.LevelDetails, showHints bool) string {
+	var sb strings.Builder
+
+	sb.WriteString("# " + utils.LevelName(key) + "\n\n")
+
+	info := []string{level.Type}
+	if level.Difficulty != "" {
+		info = append(info, level.Difficulty)
+	}
+	if level.Author != "" {
+		info = append(info, "by "+level.Author)
+	}
+	if !level.ReleaseDate.IsZero() {
+		info = append(info, "released "+level.ReleaseDate.Format("January 2, 2006"))
+	}
+	separator := " · "
+	if utils.ActiveTheme().Box.Name == utils.BoxStyleASCII {
+		separator = " - "
+	}
+	sb.WriteString("*" + strings.Join(info, separator) + "*\n\n")
+
+	if len(level.Tags) > 0 {
+		sb.WriteString("Tags: `" + strings.Join(level.Tags, "` `") + "`\n\n")
+	}
+
+	if level.Description != "" {
+		sb.WriteString(level.Description + "\n\n")
+	}
+
+	if details != nil && len(details.Interface) > 0 {
+		sb.WriteString("## Interface\n\n```solidity\n")
+		for _, signature := range details.Interface {
+			sb.WriteString(signature + ";\n")
+		}
+		sb.WriteString("```\n\n")
+	}
+
+	if len(level.Languages) > 0 {
+		sb.WriteString("Templates: " + strings.Join(level.Languages, ", ") + "\n\n")
+	}
+
+	sb.WriteString("## Scores\n\n")
+	if details == nil {
+		sb.WriteString("Loading...\n\n")
+	} else {
+		sb.WriteString(scoresMarkdown(details))
+	}
+
+	if len(level.Hints) > 0 {
+		sb.WriteString("## Hints\n\n")
+		if showHints {
+			for i, hint := range level.Hints {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, hint))
+			}
+		} else {
+			sb.WriteString(fmt.Sprintf("This level has %d hint(s). Run `evmr level show %s --hints` to see them.\n", len(level.Hints), utils.LevelName(key)))
+		}
+	}
+
+	return sb.String()
+}
+
+// returns the solves, the user's scores and the top scores of a level as markdown
+func scoresMarkdown(details *utils.LevelDetails) string {
+	var sb strings.Builder
+
+	if details.Solves != "" {
+		sb.WriteString(fmt.Sprintf("Solved by %s player(s).\n\n", details.Solves))
+	}
+
+	if details.Best != nil {
+		scores := fmt.Sprintf("Your scores: **%s** gas, **%s** bytes", details.Best.Gas, details.Best.Size)
+		if details.Best.Type != "" {
+			scores += " (" + details.Best.Type + ")"
+		}
+		sb.WriteString(scores + "\n\n")
+	} else {
+		sb.WriteString("You haven't submitted a solution yet.\n\n")
+	}
+
+	if len(details.TopGas) > 0 || len(details.TopSize) > 0 {
+		sb.WriteString("| # | Gas | By | Size | By |\n|---|---|---|---|---|\n")
+		for i := 0; i < len(details.TopGas) || i < len(details.TopSize); i++ {
+			gas, gasUser, size, sizeUser := "", "", "", ""
+			if i < len(details.TopGas) {
+				gas, gasUser = details.TopGas[i].Gas, details.TopGas[i].Username
+			}
+			if i < len(details.TopSize) {
+				size, sizeUser = details.TopSize[i].Size, details.TopSize[i].Username
+			}
+			sb.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s |\n", i+1, gas, gasUser, size, sizeUser))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// renders markdown for the terminal, wrapped at width
+func RenderMarkdown(markdown string, width int) (string, error) {
+	style := utils.ActiveTheme().MarkdownStyle
+	if !term.IsTerminal(int(os.Stdout.Fd())) && style != "ascii" {
+		style = "notty"
+	}
+
+	renderer, err := glamour.NewTermRenderer(glamour.WithStandardStyle(style), glamour.WithWordWrap(width))
+	if err != nil {
+		return "", err
+	}
+
+	return renderer.Render(markdown)
+}
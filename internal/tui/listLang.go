@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ethernautdao/evm-runners-cli/internal/utils"
 )
 
 type langListModel struct {
@@ -12,6 +13,8 @@ type langListModel struct {
 	Lang    []string
 	Cursor  int
 	Done    bool
+	// shown above the options, asks whether to use a template if not set
+	question string
 }
 
 func (m *langListModel) Init() tea.Cmd {
@@ -49,19 +52,27 @@ func (m *langListModel) View() string {
 	if m.Done {
 		return ""
 	} else {
-		sb.WriteString("Do you want to use a template?\n\n")
-		sb.WriteString("\x1b[90m┌" + strings.Repeat("─", 16) + "┐\n\x1b[0m") // Top border of the box
+		theme := utils.ActiveTheme()
+		box := theme.Box
+
+		question := m.question
+		if question == "" {
+			question = "Do you want to use a template?"
+		}
+
+		sb.WriteString(question + "\n\n")
+		sb.WriteString(theme.Muted(box.TopLeft+strings.Repeat(box.Horizontal, 16)+box.TopRight) + "\n") // Top border of the box
 		for i, option := range m.Options {
 			// Add a ">" symbol before the selected option
 			if i == m.Cursor {
-				sb.WriteString("\x1b[90m│\x1b[0m> ")
+				sb.WriteString(theme.Muted(box.Vertical) + "> ")
 			} else {
-				sb.WriteString("\x1b[90m│\x1b[0m  ")
+				sb.WriteString(theme.Muted(box.Vertical) + "  ")
 			}
-			sb.WriteString(fmt.Sprintf("%-14s\x1b[90m│\x1b[0m\n", option))
+			sb.WriteString(fmt.Sprintf("%-14s", option) + theme.Muted(box.Vertical) + "\n")
 		}
-		sb.WriteString("\x1b[90m└" + strings.Repeat("─", 16) + "┘\n\x1b[0m") // Bottom border of the box
-		sb.WriteString("\n\x1b[90m↑/↓ - Navigate | q to exit | ↩ to select \x1b[0m")
+		sb.WriteString(theme.Muted(box.BottomLeft+strings.Repeat(box.Horizontal, 16)+box.BottomRight) + "\n") // Bottom border of the box
+		sb.WriteString("\n" + theme.Muted(box.Up+"/"+box.Down+" - Navigate | q to exit | "+box.Enter+" to select "))
 		return sb.String()
 	}
 }
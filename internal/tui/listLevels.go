@@ -5,136 +5,507 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"unicode"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/ethernautdao/evm-runners-cli/internal/utils"
 )
 
+const (
+	sortByID     = "id"
+	sortBySolves = "solves"
+	sortByRank   = "my rank"
+
+	filterAll      = "all"
+	filterSolved   = "solved"
+	filterUnsolved = "unsolved"
+)
+
+var (
+	sortOrders    = []string{sortByID, sortBySolves, sortByRank}
+	solvedFilters = []string{filterAll, filterSolved, filterUnsolved}
+)
+
 type levelListModel struct {
-	Levels           map[string]utils.Level
-	solves           map[string]string
-	submissions      map[string]string
-	Keys             []string
-	Cursor           int
-	Done             bool
-	descriptionShown bool
+	Levels      map[string]utils.Level
+	solves      map[string]string
+	submissions map[string]string
+	// keys of the levels that are shown, in the order they are shown
+	Keys   []string
+	Cursor int
+	Done   bool
+	// loads the rank of the user for each solved level, used when sorting by rank
+	LoadRanks func() map[string]int
+	// loads the details of a level shown in the detail pane
+	LoadDetails func(key string) utils.LevelDetails
+	// help of the keys acting on the selected level, selecting it if not set
+	actions []string
+
+	detail levelDetailPane
+
+	// all level keys, sorted by pack and ID
+	allKeys []string
+	types   []string
+
+	query     string
+	searching bool
+	solved    string
+	levelType string
+	sortBy    string
+
+	ranks        map[string]int
+	ranksLoading bool
+
+	// terminal size, 0 if unknown
+	width  int
+	height int
+	// first line of the level rows that is shown and the number of lines shown
+	offset int
+	page   int
 }
 
+type ranksLoadedMsg map[string]int
+
 func (m *levelListModel) Init() tea.Cmd {
-	m.Keys = make([]string, 0, len(m.Levels))
+	m.allKeys = make([]string, 0, len(m.Levels))
 	for k := range m.Levels {
-		m.Keys = append(m.Keys, k)
+		m.allKeys = append(m.allKeys, k)
 	}
 
-	// Sort the keys based on the numerical value of the ID field in the utils.Level struct
-	sort.Slice(m.Keys, func(i, j int) bool {
-		idI, errI := strconv.Atoi(m.Levels[m.Keys[i]].ID)
-		if errI != nil {
-			fmt.Printf("Error converting level ID '%s' to integer: %v\n", m.Levels[m.Keys[i]].ID, errI)
-			// Consider how you want to handle this error. For simplicity, this example just prints an error message.
-		}
+	// Sort the keys by pack, official levels first, and then by the ID of the level
+	sort.Slice(m.allKeys, func(i, j int) bool {
+		return m.lessByID(m.allKeys[i], m.allKeys[j])
+	})
 
-		idJ, errJ := strconv.Atoi(m.Levels[m.Keys[j]].ID)
-		if errJ != nil {
-			fmt.Printf("Error converting level ID '%s' to integer: %v\n", m.Levels[m.Keys[j]].ID, errJ)
-			// Consider how you want to handle this error. For simplicity, this example just prints an error message.
-		}
+	types := make(map[string]bool)
+	for _, level := range m.Levels {
+		types[level.Type] = true
+	}
+	for t := range types {
+		m.types = append(m.types, t)
+	}
+	sort.Strings(m.types)
 
-		return idI < idJ
-	})
+	m.solved = filterAll
+	m.sortBy = sortByID
+	m.applyFilters()
 
 	return nil
 }
 
 func (m *levelListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.detail.resize(m.width)
+
+	case ranksLoadedMsg:
+		m.ranks = msg
+		m.ranksLoading = false
+		m.applyFilters()
+
+	case detailsLoadedMsg:
+		m.detail.loaded(msg)
+
 	case tea.KeyMsg:
+		if m.detail.shown {
+			return m.updateDetail(msg)
+		}
+
+		if m.searching {
+			switch msg.Type {
+			case tea.KeyRunes, tea.KeySpace:
+				m.query += string(msg.Runes)
+				m.applyFilters()
+				return m, nil
+			case tea.KeyBackspace:
+				if len(m.query) > 0 {
+					runes := []rune(m.query)
+					m.query = string(runes[:len(runes)-1])
+					m.applyFilters()
+				}
+				return m, nil
+			case tea.KeyEnter:
+				// keep the search and go back to navigating
+				m.searching = false
+				return m, nil
+			case tea.KeyEsc:
+				m.searching = false
+				m.query = ""
+				m.applyFilters()
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
 		case "up":
 			if m.Cursor > 0 {
 				m.Cursor--
 			}
 		case "down":
-			if m.Cursor < len(m.Levels)-1 {
+			if m.Cursor < len(m.Keys)-1 {
 				m.Cursor++
 			}
+		case "pgup":
+			m.Cursor = maxInt(m.Cursor-m.pageSize(), 0)
+		case "pgdown":
+			m.Cursor = maxInt(minInt(m.Cursor+m.pageSize(), len(m.Keys)-1), 0)
+		case "home":
+			m.Cursor = 0
+		case "end":
+			m.Cursor = maxInt(len(m.Keys)-1, 0)
 		case "right":
-			m.descriptionShown = true
-		case "left":
-			m.descriptionShown = false
+			if len(m.Keys) == 0 {
+				return m, nil
+			}
+			m.detail.actions = m.actions
+			return m, m.detail.open(m.Keys[m.Cursor], m.Levels[m.Keys[m.Cursor]], m.width, m.LoadDetails)
+		case "/":
+			m.searching = true
+		case "f":
+			m.solved = next(solvedFilters, m.solved)
+			m.applyFilters()
+		case "t":
+			m.levelType = next(append([]string{""}, m.types...), m.levelType)
+			m.applyFilters()
+		case "o":
+			m.sortBy = next(sortOrders, m.sortBy)
+			if m.sortBy == sortByRank && m.ranks == nil && m.LoadRanks != nil {
+				m.ranksLoading = true
+				return m, m.loadRanks
+			}
+			m.applyFilters()
 		case "enter":
+			if len(m.Keys) == 0 {
+				return m, nil
+			}
 			m.Done = true
 			return m, tea.Quit
-		case "ctrl+c", "q", "esc":
+		case "esc":
+			// clear the search first
+			if m.query != "" {
+				m.query = ""
+				m.applyFilters()
+				return m, nil
+			}
+			return m, tea.Quit
+		case "ctrl+c", "q":
 			return m, tea.Quit
 		}
 	}
 
-	return m, cmd
+	return m, nil
+}
+
+// handles keys while the detail pane is shown
+func (m *levelListModel) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up":
+		m.detail.scroll(-1, m.height)
+	case "down":
+		m.detail.scroll(1, m.height)
+	case "pgup":
+		m.detail.scroll(-m.detail.pageSize(m.height), m.height)
+	case "pgdown":
+		m.detail.scroll(m.detail.pageSize(m.height), m.height)
+	case "left", "esc":
+		m.detail.shown = false
+	case "enter":
+		m.Done = true
+		return m, tea.Quit
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+func (m *levelListModel) loadRanks() tea.Msg {
+	return ranksLoadedMsg(m.LoadRanks())
+}
+
+// updates the shown levels after the search, a filter or the sort order changed, keeping the selected level if it is still shown
+func (m *levelListModel) applyFilters() {
+	selected := ""
+	if m.Cursor < len(m.Keys) {
+		selected = m.Keys[m.Cursor]
+	}
+
+	m.Keys = m.Keys[:0]
+	for _, k := range m.allKeys {
+		l := m.Levels[k]
+
+		solved := m.submissions[k] != ""
+		if (m.solved == filterSolved && !solved) || (m.solved == filterUnsolved && solved) {
+			continue
+		}
+		if m.levelType != "" && l.Type != m.levelType {
+			continue
+		}
+		if m.query != "" && !fuzzyMatch(m.query, strings.ToLower(l.Contract)+" "+l.Pack) && !fuzzyMatch(m.query, l.Description) {
+			continue
+		}
+
+		m.Keys = append(m.Keys, k)
+	}
+
+	// levels stay grouped by pack
+	sort.SliceStable(m.Keys, func(i, j int) bool {
+		a, b := m.Keys[i], m.Keys[j]
+		if m.Levels[a].Pack != m.Levels[b].Pack {
+			return m.lessByID(a, b)
+		}
+
+		switch m.sortBy {
+		case sortBySolves:
+			return solveCount(m.solves[a]) > solveCount(m.solves[b])
+		case sortByRank:
+			rankA, rankB := m.ranks[a], m.ranks[b]
+			// levels without a rank come last
+			if rankA == 0 || rankB == 0 {
+				return rankA != 0 && rankB == 0
+			}
+			return rankA < rankB
+		}
+
+		return false
+	})
+
+	m.Cursor = 0
+	for i, k := range m.Keys {
+		if k == selected {
+			m.Cursor = i
+		}
+	}
+}
+
+// compares two levels by pack, official levels first, and then by ID
+func (m *levelListModel) lessByID(a string, b string) bool {
+	levelA, levelB := m.Levels[a], m.Levels[b]
+	if levelA.Pack != levelB.Pack {
+		if levelA.Pack == utils.OfficialPack || levelB.Pack == utils.OfficialPack {
+			return levelA.Pack == utils.OfficialPack
+		}
+		return levelA.Pack < levelB.Pack
+	}
+
+	idA, errA := strconv.Atoi(levelA.ID)
+	idB, errB := strconv.Atoi(levelB.ID)
+	if errA != nil || errB != nil {
+		return levelA.ID < levelB.ID
+	}
+
+	return idA < idB
+}
+
+// returns the number of solves, or -1 if it is unknown
+func solveCount(solves string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(solves))
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// reports whether all characters of the query appear in text in the same order, ignoring case and spaces of the query
+func fuzzyMatch(query string, text string) bool {
+	text = strings.ToLower(text)
+	for _, c := range strings.ToLower(query) {
+		if unicode.IsSpace(c) {
+			continue
+		}
+		i := strings.IndexRune(text, c)
+		if i < 0 {
+			return false
+		}
+		text = text[i+len(string(c)):]
+	}
+
+	return true
+}
+
+// returns the element after current, wrapping around
+func next(options []string, current string) string {
+	for i, option := range options {
+		if option == current {
+			return options[(i+1)%len(options)]
+		}
+	}
+	return options[0]
+}
+
+// returns the help of the keys acting on the selected level, selecting it by default
+func levelActions(actions []string) []string {
+	if actions == nil {
+		return []string{utils.ActiveTheme().Box.Enter + " to select"}
+	}
+	return actions
+}
+
+// number of level rows that fit the terminal, all levels if the height is unknown
+func (m *levelListModel) pageSize() int {
+	if m.page == 0 {
+		return len(m.Keys)
+	}
+	return m.page
 }
 
 func (m *levelListModel) View() string {
 	var sb strings.Builder
 
-	tableWidth := 75
-
 	if m.Done {
 		return ""
-	} else {
-		sb.WriteString("\x1b[90m┌" + strings.Repeat("─", tableWidth) + "┐\n\x1b[0m") // Top border of the box
+	}
 
-		header := fmt.Sprintf("\x1b[90m│\x1b[0m  #\t%-20s%-14s%-14s%-20s\x1b[90m│\x1b[0m\n", "NAME", "SOLVES", "SOLVED", "TYPE")
-		separator := "\x1b[90m" + "│" + strings.Repeat("─", tableWidth) + "│" + "\n" + "\x1b[0m"
+	if m.detail.shown {
+		return m.detail.view(m.height)
+	}
 
-		sb.WriteString(header)
-		sb.WriteString(separator)
+	t := newTable([]column{
+		{title: "#", width: 5},
+		{title: "NAME", width: 20, minWidth: 10},
+		{title: "SOLVES", width: 14, priority: 3},
+		{title: "SOLVED", width: 14, priority: 1},
+		{title: "TYPE", width: 20, minWidth: 8, priority: 2},
+	}, m.width)
 
-		for i, k := range m.Keys {
-			l := m.Levels[k]
-			if m.Cursor == i {
-				sb.WriteString("\x1b[90m│\x1b[0m> ")
-			} else {
-				sb.WriteString("\x1b[90m│\x1b[0m  ")
-			}
-			sb.WriteString(fmt.Sprintf("%s\t%-20s%-14s%-14s%-20s\x1b[90m│\x1b[0m\n", l.ID, strings.ToLower(l.Contract), m.solves[l.Contract], m.submissions[l.Contract], l.Type))
-			if m.Cursor == i && m.descriptionShown {
-				descriptionLines := strings.Split(l.Description, "\n")
-				separator := "\x1b[90m" + "│" + strings.Repeat("-", tableWidth) + "│" + "\n" + "\x1b[0m"
-				sb.WriteString(separator)
-				for _, line := range descriptionLines {
-					// Calculate the remaining space available for padding
-					padding := tableWidth - len(line)
-
-					// Ensure padding is not negative
-					if padding < 0 {
-						padding = 0
-					}
-
-					// Indent description lines and enclose them in the box
-					sb.WriteString("\x1b[90m│" + line + strings.Repeat(" ", padding) + "│\x1b[0m\n")
-				}
-				// dont show seperator at the last level
-				if i != len(m.Keys)-1 {
-					sb.WriteString(separator)
-				}
+	// render all rows, then show the page containing the selected level
+	var rows []string
+	cursorFirst, cursorLast := 0, 0
+	multiplePacks := m.hasMultiplePacks()
+
+	for i, k := range m.Keys {
+		l := m.Levels[k]
+
+		// the selected level includes its pack header, so the header is scrolled into view with it
+		if m.Cursor == i {
+			cursorFirst = len(rows)
+		}
+
+		// group the levels by pack if more than one pack is installed
+		if multiplePacks && (i == 0 || m.Levels[m.Keys[i-1]].Pack != l.Pack) {
+			if i != 0 && !t.plain {
+				rows = append(rows, t.separator())
 			}
+			rows = append(rows, t.line(strings.ToUpper(l.Pack), true))
+		}
+
+		cursor := "  "
+		if m.Cursor == i {
+			cursor = "> "
+		}
+
+		solved := m.submissions[k]
+		if rank, ok := m.ranks[k]; ok {
+			solved = fmt.Sprintf("#%d", rank)
 		}
+		rows = append(rows, t.row(cursor, []string{l.ID, strings.ToLower(l.Contract), strings.TrimSpace(m.solves[k]), solved, l.Type}))
 
-		sb.WriteString("\x1b[90m└" + strings.Repeat("─", tableWidth) + "┘\n\x1b[0m") // Bottom border of the box
+		if m.Cursor == i {
+			cursorLast = len(rows) - 1
+		}
+	}
 
-		sb.WriteString("\n\x1b[90m↑/↓ - Navigate | ←/→ - Toggle Description | q to exit | ↩ to select \x1b[0m")
+	if len(m.Keys) == 0 {
+		rows = append(rows, t.line("No levels match.", false))
+	}
 
-		return sb.String()
+	box := t.theme.Box
+	help := []string{box.Up + "/" + box.Down + " - Navigate", "PgUp/PgDn - Page", box.Right + " - Details"}
+	help = append(help, levelActions(m.actions)...)
+	help = append(help, "/ - Search", "f - Solved", "t - Type", "o - Sort", "q to exit")
+	helpText := joinWrapped(help, " | ", m.width)
+
+	// scroll so that the selected level is shown
+	page := len(rows)
+	if m.height > 0 {
+		status := m.statusLine(true)
+		chrome := strings.Count(t.top()+t.header()+t.separator()+t.bottom()+status, "\n") + strings.Count(helpText, "\n") + 2
+		page = maxInt(m.height-chrome, 3)
 	}
+	m.page = page
+
+	if cursorLast >= m.offset+page {
+		m.offset = cursorLast - page + 1
+	}
+	if cursorFirst < m.offset {
+		m.offset = cursorFirst
+	}
+	m.offset = maxInt(minInt(m.offset, len(rows)-page), 0)
+
+	sb.WriteString(t.top())
+	sb.WriteString(t.header())
+	sb.WriteString(t.separator())
+
+	for _, row := range rows[m.offset:minInt(m.offset+page, len(rows))] {
+		sb.WriteString(row)
+	}
+
+	sb.WriteString(t.bottom())
+	sb.WriteString(m.statusLine(len(rows) > page))
+	sb.WriteString("\n" + t.theme.Muted(helpText))
+
+	return sb.String()
 }
 
-func NewLevelList(Levels map[string]utils.Level, solves map[string]string, submissions map[string]string) (*levelListModel, error) {
-	// Check terminal size
-	if err := utils.CheckMinTerminalWidth(); err != nil {
-		return nil, err
+// returns the line below the table with the search, filters and sort order
+func (m *levelListModel) statusLine(paged bool) string {
+	var parts []string
+
+	if m.searching {
+		parts = append(parts, "search: "+m.query+utils.ActiveTheme().Box.Cursor)
+	} else if m.query != "" {
+		parts = append(parts, "search: "+m.query)
+	}
+
+	levelType := m.levelType
+	if levelType == "" {
+		levelType = filterAll
+	}
+	parts = append(parts, "solved: "+m.solved, "type: "+levelType)
+
+	sortBy := m.sortBy
+	if m.ranksLoading {
+		sortBy += " (loading...)"
 	}
+	parts = append(parts, "sort: "+sortBy)
 
-	return &levelListModel{Levels: Levels, solves: solves, submissions: submissions}, nil
+	count := fmt.Sprintf("%d/%d levels", len(m.Keys), len(m.allKeys))
+	if paged && len(m.Keys) > 0 {
+		count = fmt.Sprintf("level %d of %d", m.Cursor+1, len(m.Keys))
+	}
+	parts = append(parts, count)
+
+	return " " + joinWrapped(parts, " | ", m.width-1) + "\n"
+}
+
+// reports whether the levels belong to more than one pack
+func (m *levelListModel) hasMultiplePacks() bool {
+	for _, k := range m.allKeys {
+		if m.Levels[k].Pack != m.Levels[m.allKeys[0]].Pack {
+			return true
+		}
+	}
+
+	return false
+}
+
+func NewLevelList(Levels map[string]utils.Level, solves map[string]string, submissions map[string]string) *levelListModel {
+	width, height := utils.TerminalSize()
+
+	return &levelListModel{Levels: Levels, solves: solves, submissions: submissions, width: width, height: height}
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
 }
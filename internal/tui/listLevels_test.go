@@ -0,0 +1,30 @@
+package tui
+
+import "testing"
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+		want  bool
+	}{
+		{"", "Average", true},
+		{"avg", "Average", true},
+		{"AVG", "average", true},
+		{"av g", "Average", true},
+		{"gva", "Average", false},
+		{"avgx", "Average", false},
+		// each character of the text matches only once
+		{"ee", "Average", true},
+		{"vv", "Average", false},
+		{"gea", "Average", false},
+		{"ff", "Fib FFT", true},
+		{"über", "Überlauf", true},
+	}
+
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.query, tt.text); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.query, tt.text, got, tt.want)
+		}
+	}
+}
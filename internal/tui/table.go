@@ -0,0 +1,203 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/ethernautdao/evm-runners-cli/internal/utils"
+	"github.com/muesli/reflow/ansi"
+	"github.com/muesli/reflow/truncate"
+)
+
+const (
+	// tables that don't fit into this width are rendered as plain text without a box
+	minTableWidth = 30
+	// width of the cursor in front of each row, e.g. "> "
+	cursorWidth = 2
+)
+
+// a column of a table
+type column struct {
+	title string
+	width int
+	// width the column can be truncated to if the table doesn't fit, 0 if it can't be truncated
+	minWidth int
+	// columns are hidden by priority, highest first, if the table doesn't fit. Columns with priority 0 are never hidden.
+	priority int
+}
+
+// a table laid out for the width of the terminal
+type table struct {
+	columns []column
+	// indexes of the columns that are shown
+	shown []int
+	// width inside the box
+	width int
+	plain bool
+	theme utils.Theme
+}
+
+// lays out a table for a terminal width, which is 0 if it is unknown
+func newTable(columns []column, terminalWidth int) table {
+	t := table{columns: append([]column(nil), columns...), theme: utils.ActiveTheme()}
+	for i := range columns {
+		t.shown = append(t.shown, i)
+	}
+
+	if terminalWidth <= 0 {
+		t.width = t.contentWidth()
+		return t
+	}
+
+	// the borders of the box take 2 columns
+	available := terminalWidth - 2
+
+	for t.contentWidth() > available {
+		hide := -1
+		for i, c := range t.shown {
+			if t.columns[c].priority > 0 && (hide < 0 || t.columns[c].priority > t.columns[t.shown[hide]].priority) {
+				hide = i
+			}
+		}
+		if hide < 0 {
+			break
+		}
+		t.shown = append(t.shown[:hide], t.shown[hide+1:]...)
+	}
+
+	for _, c := range t.shown {
+		excess := t.contentWidth() - available
+		if excess > 0 && t.columns[c].minWidth > 0 {
+			t.columns[c].width = maxInt(t.columns[c].width-excess, t.columns[c].minWidth)
+		}
+	}
+
+	t.width = t.contentWidth()
+	t.plain = t.width > available || available < minTableWidth
+
+	return t
+}
+
+func (t table) contentWidth() int {
+	width := cursorWidth
+	for _, c := range t.shown {
+		width += t.columns[c].width
+	}
+	return width
+}
+
+func (t table) top() string {
+	if t.plain {
+		return ""
+	}
+	box := t.theme.Box
+	return t.theme.Muted(box.TopLeft+strings.Repeat(box.Horizontal, t.width)+box.TopRight) + "\n"
+}
+
+func (t table) bottom() string {
+	if t.plain {
+		return ""
+	}
+	box := t.theme.Box
+	return t.theme.Muted(box.BottomLeft+strings.Repeat(box.Horizontal, t.width)+box.BottomRight) + "\n"
+}
+
+func (t table) separator() string {
+	if t.plain {
+		return ""
+	}
+	box := t.theme.Box
+	return t.theme.Muted(box.Vertical+strings.Repeat(box.Horizontal, t.width)+box.Vertical) + "\n"
+}
+
+func (t table) header() string {
+	titles := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		titles[i] = c.title
+	}
+	return t.row("  ", titles)
+}
+
+// renders a row, cursor is shown in front of it, e.g. "> ". Cells of hidden columns are left out.
+func (t table) row(cursor string, cells []string) string {
+	var sb strings.Builder
+
+	if t.plain {
+		sb.WriteString(cursor)
+		var shown []string
+		for _, c := range t.shown {
+			if cells[c] != "" {
+				shown = append(shown, cells[c])
+			}
+		}
+		sb.WriteString(strings.Join(shown, "  ") + "\n")
+		return sb.String()
+	}
+
+	sb.WriteString(t.theme.Muted(t.theme.Box.Vertical) + cursor)
+	for _, c := range t.shown {
+		// keep a space between the columns
+		sb.WriteString(fit(cells[c], t.columns[c].width-1) + " ")
+	}
+	sb.WriteString(t.theme.Muted(t.theme.Box.Vertical) + "\n")
+
+	return sb.String()
+}
+
+// renders a row with a single text spanning all columns, e.g. a group header
+func (t table) line(text string, bold bool) string {
+	style := func(s string) string { return s }
+	if bold {
+		style = t.theme.Bold
+	}
+
+	if t.plain {
+		return style(text) + "\n"
+	}
+	return t.theme.Muted(t.theme.Box.Vertical) + "  " + style(fit(text, t.width-2)) + t.theme.Muted(t.theme.Box.Vertical) + "\n"
+}
+
+// truncates or pads s to width, ignoring escape sequences
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+
+	if ansi.PrintableRuneWidth(s) > width {
+		s = truncate.StringWithTail(s, uint(width), "..")
+	}
+
+	return s + strings.Repeat(" ", maxInt(width-ansi.PrintableRuneWidth(s), 0))
+}
+
+// centers s in width
+func center(s string, width int) string {
+	padding := maxInt((width-ansi.PrintableRuneWidth(s))/2, 0)
+	return strings.Repeat(" ", padding) + s
+}
+
+// joins items with sep, starting a new line instead of splitting an item if a line would be wider than width.
+// The last column of the terminal is left empty, as some terminals draw symbols like ↩ wider than expected.
+func joinWrapped(items []string, sep string, width int) string {
+	if width <= 0 {
+		return strings.Join(items, sep)
+	}
+
+	var sb strings.Builder
+	lineWidth := 0
+	for i, item := range items {
+		itemWidth := ansi.PrintableRuneWidth(item)
+		if i > 0 {
+			if lineWidth+ansi.PrintableRuneWidth(sep)+itemWidth >= width {
+				sb.WriteString("\n")
+				lineWidth = 0
+			} else {
+				sb.WriteString(sep)
+				lineWidth += ansi.PrintableRuneWidth(sep)
+			}
+		}
+		sb.WriteString(item)
+		lineWidth += itemWidth
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muesli/reflow/ansi"
+)
+
+var testColumns = []column{
+	{title: "Level", width: 20, minWidth: 10},
+	{title: "Gas", width: 10, priority: 1},
+	{title: "Size", width: 10, priority: 2},
+	{title: "Solved", width: 15},
+}
+
+// returns the titles of the columns that are shown
+func shownTitles(t table) []string {
+	var titles []string
+	for _, c := range t.shown {
+		titles = append(titles, t.columns[c].title)
+	}
+	return titles
+}
+
+func TestNewTableLayout(t *testing.T) {
+	tests := []struct {
+		terminalWidth int
+		shown         string
+		levelWidth    int
+		plain         bool
+	}{
+		// unknown terminal width, everything is shown
+		{0, "Level Gas Size Solved", 20, false},
+		{100, "Level Gas Size Solved", 20, false},
+		{59, "Level Gas Size Solved", 20, false},
+		// the column with the highest priority is hidden first
+		{58, "Level Gas Solved", 20, false},
+		{48, "Level Solved", 20, false},
+		// then columns are truncated, but not below their minimum width
+		{38, "Level Solved", 19, false},
+		{32, "Level Solved", 13, false},
+		// tables that still don't fit are rendered as plain text
+		{31, "Level Solved", 12, true},
+		{25, "Level Solved", 10, true},
+	}
+
+	for _, tt := range tests {
+		table := newTable(testColumns, tt.terminalWidth)
+
+		if got := strings.Join(shownTitles(table), " "); got != tt.shown {
+			t.Errorf("width %d: shown columns = %q, want %q", tt.terminalWidth, got, tt.shown)
+		}
+		if got := table.columns[0].width; got != tt.levelWidth {
+			t.Errorf("width %d: level column width = %d, want %d", tt.terminalWidth, got, tt.levelWidth)
+		}
+		if table.plain != tt.plain {
+			t.Errorf("width %d: plain = %v, want %v", tt.terminalWidth, table.plain, tt.plain)
+		}
+
+		// rows of boxed tables fill the terminal width at most
+		if !table.plain && tt.terminalWidth > 0 {
+			for _, line := range strings.Split(strings.TrimSuffix(table.top()+table.header()+table.bottom(), "\n"), "\n") {
+				if width := ansi.PrintableRuneWidth(line); width != table.width+2 || width > tt.terminalWidth {
+					t.Errorf("width %d: line %q is %d wide, want %d", tt.terminalWidth, line, width, table.width+2)
+				}
+			}
+		}
+	}
+
+	// the columns passed in are not modified
+	if testColumns[0].width != 20 {
+		t.Errorf("newTable modified the columns")
+	}
+}
+
+func TestTableRowTruncatesCells(t *testing.T) {
+	table := newTable(testColumns, 32)
+
+	row := table.row("> ", []string{"AVeryLongLevelNameThatDoesNotFit", "100", "200", "x"})
+	if width := ansi.PrintableRuneWidth(strings.TrimSuffix(row, "\n")); width != table.width+2 {
+		t.Errorf("row is %d wide, want %d: %q", width, table.width+2, row)
+	}
+	if !strings.Contains(row, "AVeryLongL..") {
+		t.Errorf("long cell was not truncated: %q", row)
+	}
+	if strings.Contains(row, "200") {
+		t.Errorf("cell of a hidden column was rendered: %q", row)
+	}
+
+	plain := newTable(testColumns, 25)
+	if got := plain.row("> ", []string{"Average", "100", "200", ""}); got != "> Average\n" {
+		t.Errorf("plain row = %q, want %q", got, "> Average\n")
+	}
+}
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// returned if the auth token is missing, or was rejected by the server because it is invalid or expired
+type AuthError struct {
+	Reason string
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("%s. Run 'evmr auth' to authenticate.\n", e.Reason)
+}
+
+// reports whether an error means that the user has to authenticate again
+func IsAuthError(err error) bool {
+	var authErr *AuthError
+	return errors.As(err, &authErr)
+}
+
+// error codes of a rejected auth token, see RFC 6750
+var invalidTokenCodes = []string{"invalid_token", "token_expired"}
+
+// called by DoAuthorized if the auth token was rejected and couldn't be refreshed. Authenticates again and sets the
+// new tokens in the config, or returns an error if that isn't possible. Nil disables re-authentication, e.g. in the TUI.
+var Reauthenticate func(config *Config) error
+
+// reports whether the server rejected the auth token. Servers respond with 401, older server versions
+// respond with 400, which is only treated as a rejected token if the response has an invalid token error code.
+// 403 means that the token is valid, but the user isn't allowed to do something.
+func isUnauthorized(resp *http.Response, body []byte) bool {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return true
+	case http.StatusBadRequest:
+		// e.g. 'WWW-Authenticate: Bearer error="invalid_token"'
+		challenge := resp.Header.Get("WWW-Authenticate")
+		for _, code := range invalidTokenCodes {
+			if strings.Contains(challenge, `error="`+code+`"`) {
+				return true
+			}
+		}
+
+		// e.g. {"error": "invalid_token"}
+		var response struct {
+			Error string `json:"error"`
+			Code  string `json:"code"`
+		}
+		if json.Unmarshal(body, &response) != nil {
+			return false
+		}
+		for _, code := range invalidTokenCodes {
+			if response.Error == code || response.Code == code {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// sends a request with the auth token of the config and returns the response and its body.
+// If the token was rejected, it is renewed with the refresh token or by authenticating again, and the request is retried once.
+func DoAuthorized(config *Config, req *http.Request, client *http.Client) (*http.Response, []byte, error) {
+	if err := LoadCredentials(config); err != nil {
+		return nil, nil, err
+	}
+
+	if config.EVMR_TOKEN == "" {
+		return nil, nil, &AuthError{Reason: "You are not authenticated"}
+	}
+
+	resp, body, err := doWithToken(req, client, config.EVMR_TOKEN)
+	if err != nil || !isUnauthorized(resp, body) {
+		return resp, body, err
+	}
+
+	rejected := &AuthError{Reason: fmt.Sprintf("Your auth token was rejected by the server (%s), it has probably expired", resp.Status)}
+
+	if err := renewAuthToken(config); err != nil {
+		return resp, body, rejected
+	}
+
+	// requests with a body can only be sent again if the body can be recreated
+	retry := req.Clone(req.Context())
+	if req.Body != nil {
+		if req.GetBody == nil {
+			return resp, body, rejected
+		}
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, body, rejected
+		}
+	}
+
+	resp, body, err = doWithToken(retry, client, config.EVMR_TOKEN)
+	if err == nil && isUnauthorized(resp, body) {
+		return resp, body, rejected
+	}
+
+	return resp, body, err
+}
+
+// renews a rejected auth token with the refresh token, or by authenticating again if that isn't possible
+func renewAuthToken(config *Config) error {
+	if config.EVMR_REFRESH_TOKEN != "" {
+		if err := RefreshAuthToken(config); err == nil {
+			return nil
+		}
+	}
+
+	if Reauthenticate == nil {
+		return fmt.Errorf("can't authenticate again")
+	}
+
+	return Reauthenticate(config)
+}
+
+// sends a request with an auth token and reads the response body
+func doWithToken(req *http.Request, client *http.Client, token string) (*http.Response, []byte, error) {
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error sending the request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, nil, fmt.Errorf("error reading the response: %v", err)
+	}
+
+	return resp, body, nil
+}
+
+// renews the auth token with the refresh token and stores the new tokens
+func RefreshAuthToken(config *Config) error {
+	payload, _ := json.Marshal(map[string]string{"refresh_token": config.EVMR_REFRESH_TOKEN})
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+	}
+
+	resp, err := client.Post(config.EVMR_SERVER+"auth/refresh", "application/json", bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("error sending the request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to refresh auth token: %s", resp.Status)
+	}
+
+	var tokens struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return fmt.Errorf("error parsing the response: %v", err)
+	}
+
+	if tokens.AccessToken == "" {
+		return fmt.Errorf("failed to refresh auth token: no token in response")
+	}
+
+	config.EVMR_TOKEN = tokens.AccessToken
+	if _, err := SetConfigValue("EVMR_TOKEN", tokens.AccessToken); err != nil {
+		return err
+	}
+
+	// the server may rotate the refresh token
+	if tokens.RefreshToken != "" {
+		config.EVMR_REFRESH_TOKEN = tokens.RefreshToken
+		if _, err := SetConfigValue("EVMR_REFRESH_TOKEN", tokens.RefreshToken); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
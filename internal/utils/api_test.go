@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsUnauthorized(t *testing.T) {
+	tests := []struct {
+		status    int
+		header    string
+		body      string
+		wantAuthz bool
+	}{
+		{http.StatusUnauthorized, "", "", true},
+		// the token is valid, but the user isn't allowed to do something
+		{http.StatusForbidden, "", "", false},
+		{http.StatusForbidden, "", `{"error":"forbidden"}`, false},
+		{http.StatusOK, "", `{"error":"invalid_token"}`, false},
+		// genuine bad requests may mention tokens, e.g. a solution that uses an ERC20 token
+		{http.StatusBadRequest, "", `{"error":"bytecode deploys an invalid token contract"}`, false},
+		{http.StatusBadRequest, "", "jwt expired", false},
+		{http.StatusBadRequest, "", `{"error":"invalid_token"}`, true},
+		{http.StatusBadRequest, "", `{"code":"token_expired","message":"jwt expired"}`, true},
+		{http.StatusBadRequest, `Bearer error="invalid_token", error_description="expired"`, "", true},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: make(http.Header)}
+		if tt.header != "" {
+			resp.Header.Set("WWW-Authenticate", tt.header)
+		}
+
+		if got := isUnauthorized(resp, []byte(tt.body)); got != tt.wantAuthz {
+			t.Errorf("isUnauthorized(%d, %q, %q) = %v, want %v", tt.status, tt.header, tt.body, got, tt.wantAuthz)
+		}
+	}
+}
+
+// serves GET /data, which only accepts the token "valid", and POST /auth/refresh, which issues it
+func newTokenServer(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/data", func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if r.Header.Get("Authorization") != "Bearer valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/auth/refresh", func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload.RefreshToken != "refresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "valid", "refresh_token": "rotated"})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestDoAuthorizedRefreshesToken(t *testing.T) {
+	home := setupTestHome(t)
+	path := filepath.Join(home, configFile)
+	writeTestConfig(t, path, map[string]string{"EVMR_LEVELS_DIR": home, "EVMR_CREDENTIAL_STORE": CredentialStorePlaintext})
+
+	var requests int
+	server := newTokenServer(t, &requests)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.EVMR_SERVER = server.URL + "/"
+	config.EVMR_TOKEN = "expired"
+	config.EVMR_REFRESH_TOKEN = "refresh"
+
+	req, _ := http.NewRequest("GET", server.URL+"/data", nil)
+	resp, body, err := DoAuthorized(&config, req, server.Client())
+	if err != nil {
+		t.Fatalf("DoAuthorized() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Errorf("DoAuthorized() = %s %q", resp.Status, body)
+	}
+	if requests != 2 {
+		t.Errorf("%d requests, want the request and one retry", requests)
+	}
+	if config.EVMR_TOKEN != "valid" || config.EVMR_REFRESH_TOKEN != "rotated" {
+		t.Errorf("tokens = %q, %q, want the refreshed tokens", config.EVMR_TOKEN, config.EVMR_REFRESH_TOKEN)
+	}
+
+	v, err := readEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.GetString("EVMR_TOKEN") != "valid" || v.GetString("EVMR_REFRESH_TOKEN") != "rotated" {
+		t.Errorf("refreshed tokens were not stored: %v", v.AllSettings())
+	}
+}
+
+func TestDoAuthorizedReauthenticates(t *testing.T) {
+	setupTestHome(t)
+
+	var requests int
+	server := newTokenServer(t, &requests)
+
+	var reauths int
+	Reauthenticate = func(config *Config) error {
+		reauths++
+		config.EVMR_TOKEN = "valid"
+		return nil
+	}
+	t.Cleanup(func() { Reauthenticate = nil })
+
+	config := Config{EVMR_SERVER: server.URL + "/", EVMR_TOKEN: "expired", credentialsLoaded: true}
+
+	// requests with a body are sent again with the same body
+	req, _ := http.NewRequest("POST", server.URL+"/data", bytes.NewBufferString(`{"bytecode":"0x00"}`))
+	resp, _, err := DoAuthorized(&config, req, server.Client())
+	if err != nil {
+		t.Fatalf("DoAuthorized() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || reauths != 1 || requests != 2 {
+		t.Errorf("status %s after %d re-authentications and %d requests", resp.Status, reauths, requests)
+	}
+}
+
+func TestDoAuthorizedRejectedToken(t *testing.T) {
+	setupTestHome(t)
+
+	var requests int
+	server := newTokenServer(t, &requests)
+
+	config := Config{EVMR_SERVER: server.URL + "/", EVMR_TOKEN: "expired", credentialsLoaded: true}
+
+	req, _ := http.NewRequest("GET", server.URL+"/data", nil)
+	_, _, err := DoAuthorized(&config, req, server.Client())
+	if !IsAuthError(err) {
+		t.Errorf("DoAuthorized() error = %v, want an auth error", err)
+	}
+
+	config.EVMR_TOKEN = ""
+	if _, _, err := DoAuthorized(&config, req, server.Client()); !IsAuthError(err) {
+		t.Errorf("DoAuthorized() without token error = %v, want an auth error", err)
+	}
+	if requests != 1 {
+		t.Errorf("%d requests, a missing token shouldn't be sent", requests)
+	}
+}
+
+func TestFetchSubmissionDataBadRequest(t *testing.T) {
+	setupTestHome(t)
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(server.Close)
+
+	reauthenticated := false
+	Reauthenticate = func(config *Config) error {
+		reauthenticated = true
+		return nil
+	}
+	t.Cleanup(func() { Reauthenticate = nil })
+
+	config := Config{EVMR_SERVER: server.URL + "/", EVMR_TOKEN: "token", credentialsLoaded: true}
+	_, err := FetchSubmissionData(&config)
+	if err == nil || !strings.Contains(err.Error(), "evmr auth") {
+		t.Errorf("FetchSubmissionData() error = %v, want a hint to authenticate again", err)
+	}
+	if reauthenticated || requests != 1 {
+		t.Errorf("a 400 without an error code started re-authentication")
+	}
+}
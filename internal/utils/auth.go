@@ -0,0 +1,308 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultAuthProvider = "discord"
+
+// response of the server after a successful authentication
+type AuthResponse struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type AuthOptions struct {
+	// don't open a browser, only print the URL to open
+	NoBrowser bool
+	// keystore file or file with a hex encoded private key, used to sign in with Ethereum
+	Keystore string
+	KeyFile  string
+}
+
+// authenticates the user with a platform. Endpoints are relative to EVMR_SERVER,
+// so every provider can be used with a local server by setting --server.
+type AuthProvider interface {
+	// name used on the command line, e.g. "discord"
+	Name() string
+	// name shown in messages, e.g. "Discord"
+	DisplayName() string
+	Aliases() []string
+	Authenticate(config Config, opts AuthOptions) (AuthResponse, error)
+}
+
+// all available auth providers. The first one is the default.
+var AuthProviders = []AuthProvider{
+	deviceFlowProvider{
+		name:        "discord",
+		displayName: "Discord",
+		aliases:     []string{"d"},
+		devicePath:  "auth/device",
+		pinPath:     "auth",
+	},
+	deviceFlowProvider{
+		name:        "github",
+		displayName: "GitHub",
+		aliases:     []string{"gh"},
+		devicePath:  "auth/github/device",
+	},
+	ethereumProvider{},
+}
+
+// returns the auth provider with the given name or alias
+func GetAuthProvider(name string) (AuthProvider, error) {
+	name = strings.ToLower(name)
+	for _, provider := range AuthProviders {
+		if provider.Name() == name {
+			return provider, nil
+		}
+		for _, alias := range provider.Aliases() {
+			if alias == name {
+				return provider, nil
+			}
+		}
+	}
+
+	var names []string
+	for _, provider := range AuthProviders {
+		names = append(names, provider.Name())
+	}
+
+	return nil, fmt.Errorf("Invalid authentication method '%s'. Use one of: %s\n", name, strings.Join(names, ", "))
+}
+
+// authenticates with a provider and stores the auth tokens, user ID and name
+func Authenticate(config Config, provider AuthProvider, opts AuthOptions) (AuthResponse, error) {
+	authResp, err := provider.Authenticate(config, opts)
+	if err != nil {
+		return authResp, err
+	}
+
+	// set or overwrite config elements
+	config.EVMR_ID = authResp.ID
+	config.EVMR_NAME = authResp.Name
+	config.EVMR_TOKEN = authResp.AccessToken
+	config.EVMR_REFRESH_TOKEN = authResp.RefreshToken
+	config.EVMR_AUTH_PROVIDER = provider.Name()
+
+	// save config
+	if err := WriteConfig(config); err != nil {
+		return authResp, fmt.Errorf("failed to save auth data: %v", err)
+	}
+
+	return authResp, nil
+}
+
+// response of the device authorization endpoint
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// error response of the device token endpoint while the login is not completed
+type DeviceTokenError struct {
+	Error string `json:"error"`
+}
+
+// returned if the server doesn't support the device flow
+var errDeviceFlowUnsupported = errors.New("device flow not supported by server")
+
+// logs in via a website: the user opens a URL on any device and enters a short code,
+// while the token endpoint is polled until the login is completed
+type deviceFlowProvider struct {
+	name        string
+	displayName string
+	aliases     []string
+	// endpoint that starts the device flow, the token endpoint is devicePath + "/token"
+	devicePath string
+	// page that shows a PIN after logging in, used if the server doesn't support the device flow
+	pinPath string
+}
+
+func (p deviceFlowProvider) Name() string {
+	return p.name
+}
+
+func (p deviceFlowProvider) DisplayName() string {
+	return p.displayName
+}
+
+func (p deviceFlowProvider) Aliases() []string {
+	return p.aliases
+}
+
+func (p deviceFlowProvider) Authenticate(config Config, opts AuthOptions) (AuthResponse, error) {
+	authResp, err := p.authDeviceFlow(config, opts.NoBrowser)
+	if errors.Is(err, errDeviceFlowUnsupported) {
+		if p.pinPath == "" {
+			return authResp, fmt.Errorf("the server doesn't support authentication with %s", p.displayName)
+		}
+
+		// older servers only support entering a PIN
+		return p.authPin(config, opts.NoBrowser)
+	}
+
+	return authResp, err
+}
+
+func (p deviceFlowProvider) authDeviceFlow(config Config, noBrowser bool) (AuthResponse, error) {
+	var authResp AuthResponse
+
+	resp, err := http.Post(config.EVMR_SERVER+p.devicePath, "application/json", nil)
+	if err != nil {
+		return authResp, fmt.Errorf("error making POST request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+		return authResp, errDeviceFlowUnsupported
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return authResp, fmt.Errorf("failed to start authentication: %s", resp.Status)
+	}
+
+	var device DeviceCodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&device); err != nil {
+		return authResp, fmt.Errorf("error unmarshalling response body: %v", err)
+	}
+
+	theme := ActiveTheme()
+	fmt.Printf("To authenticate with %s, open\n\n  %s\n\nand enter the code\n\n  %s\n\n", p.displayName, theme.Link(device.VerificationURI), theme.Bold(device.UserCode))
+
+	browserURL := device.VerificationURIComplete
+	if browserURL == "" {
+		browserURL = device.VerificationURI
+	}
+	openBrowser(browserURL, noBrowser)
+
+	fmt.Println("Waiting for you to complete the login...")
+
+	return p.pollDeviceToken(config, device)
+}
+
+// polls the token endpoint until the user completed the login or the device code expired
+func (p deviceFlowProvider) pollDeviceToken(config Config, device DeviceCodeResponse) (AuthResponse, error) {
+	var authResp AuthResponse
+
+	interval := time.Duration(device.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	expiresIn := time.Duration(device.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = 15 * time.Minute
+	}
+	deadline := time.Now().Add(expiresIn)
+
+	expired := fmt.Errorf("the code expired, please run 'evmr auth %s' again", p.name)
+	payload, _ := json.Marshal(map[string]string{"device_code": device.DeviceCode})
+
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+
+		resp, err := http.Post(config.EVMR_SERVER+p.devicePath+"/token", "application/json", bytes.NewBuffer(payload))
+		if err != nil {
+			return authResp, fmt.Errorf("error making POST request: %v", err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return authResp, fmt.Errorf("error reading response body: %v", err)
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			if err := json.Unmarshal(body, &authResp); err != nil {
+				return authResp, fmt.Errorf("error unmarshalling response body: %v", err)
+			}
+			return authResp, nil
+		}
+
+		var tokenErr DeviceTokenError
+		_ = json.Unmarshal(body, &tokenErr)
+
+		switch tokenErr.Error {
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+		case "expired_token":
+			return authResp, expired
+		case "access_denied":
+			return authResp, fmt.Errorf("the login was denied")
+		default:
+			return authResp, fmt.Errorf("failed to authenticate with server: %s", resp.Status)
+		}
+	}
+
+	return authResp, expired
+}
+
+// authenticates by opening the auth page and asking for the PIN shown after logging in
+func (p deviceFlowProvider) authPin(config Config, noBrowser bool) (AuthResponse, error) {
+	var authResp AuthResponse
+
+	// get URL to open in the browser
+	url := config.EVMR_SERVER + p.pinPath
+	fmt.Printf("To authenticate with %s, open %s\n", p.displayName, ActiveTheme().Link(url))
+	openBrowser(url, noBrowser)
+
+	fmt.Println("When you're done authenticating, enter the provided PIN code")
+
+	// read PIN from stdin
+	var pin string
+	fmt.Printf("\nPIN: ")
+	if _, err := fmt.Scanln(&pin); err != nil {
+		return authResp, fmt.Errorf("failed to read PIN: %v", err)
+	}
+
+	// make GET request to server
+	tokenUrl := fmt.Sprintf("%susers/info/%s", config.EVMR_SERVER, pin)
+	resp, err := http.Get(tokenUrl)
+	if err != nil {
+		return authResp, fmt.Errorf("error making GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return authResp, fmt.Errorf("failed to authenticate with server: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return authResp, fmt.Errorf("error reading response body: %v", err)
+	}
+
+	// unmarshal response body
+	if err := json.Unmarshal(body, &authResp); err != nil {
+		return authResp, fmt.Errorf("error unmarshalling response body: %v", err)
+	}
+
+	return authResp, nil
+}
+
+// opens a URL in the browser unless disabled or no browser is available
+func openBrowser(url string, noBrowser bool) {
+	if noBrowser || !HasDisplay() {
+		return
+	}
+
+	if err := OpenBrowser(url); err != nil {
+		fmt.Printf("Could not open a browser (%v), please open the URL manually.\n", err)
+	}
+}
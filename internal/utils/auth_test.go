@@ -0,0 +1,190 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// replaces stdin with a file containing the given input
+func setStdin(t *testing.T, input string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte(input), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdin := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		f.Close()
+	})
+}
+
+func TestDeviceFlow(t *testing.T) {
+	var polls int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/device", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		json.NewEncoder(w).Encode(DeviceCodeResponse{
+			DeviceCode:      "device-123",
+			UserCode:        "ABCD-EFGH",
+			VerificationURI: "https://evmr.sh/device",
+			ExpiresIn:       60,
+			Interval:        1,
+		})
+	})
+	mux.HandleFunc("/auth/device/token", func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload["device_code"] != "device-123" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(DeviceTokenError{Error: "invalid_grant"})
+			return
+		}
+
+		// the user completes the login after the first poll
+		polls++
+		if polls == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(DeviceTokenError{Error: "authorization_pending"})
+			return
+		}
+		json.NewEncoder(w).Encode(AuthResponse{ID: "5", Name: "alice", AccessToken: "access", RefreshToken: "refresh"})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider, err := GetAuthProvider("d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authResp, err := provider.Authenticate(Config{EVMR_SERVER: server.URL + "/"}, AuthOptions{NoBrowser: true})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if authResp.Name != "alice" || authResp.AccessToken != "access" || authResp.RefreshToken != "refresh" {
+		t.Errorf("Authenticate() = %+v", authResp)
+	}
+	if polls != 2 {
+		t.Errorf("token endpoint polled %d times, want 2", polls)
+	}
+}
+
+func TestDeviceFlowDenied(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/github/device", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(DeviceCodeResponse{DeviceCode: "device", Interval: 1})
+	})
+	mux.HandleFunc("/auth/github/device/token", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(DeviceTokenError{Error: "access_denied"})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider, _ := GetAuthProvider("github")
+	if _, err := provider.Authenticate(Config{EVMR_SERVER: server.URL + "/"}, AuthOptions{NoBrowser: true}); err == nil {
+		t.Errorf("Authenticate() succeeded although the login was denied")
+	}
+}
+
+func TestPinFallback(t *testing.T) {
+	mux := http.NewServeMux()
+	// older servers have no device flow
+	mux.HandleFunc("/auth/device", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/users/info/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/info/1234" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(AuthResponse{ID: "5", Name: "alice", AccessToken: "access"})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	setStdin(t, "1234\n")
+
+	provider, _ := GetAuthProvider("discord")
+	authResp, err := provider.Authenticate(Config{EVMR_SERVER: server.URL + "/"}, AuthOptions{NoBrowser: true})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if authResp.ID != "5" || authResp.AccessToken != "access" {
+		t.Errorf("Authenticate() = %+v", authResp)
+	}
+
+	// GitHub has no PIN page
+	provider, _ = GetAuthProvider("gh")
+	if _, err := provider.Authenticate(Config{EVMR_SERVER: server.URL + "/"}, AuthOptions{NoBrowser: true}); err == nil {
+		t.Errorf("Authenticate() with GitHub succeeded without device flow")
+	}
+}
+
+func TestAuthenticateStoresTokens(t *testing.T) {
+	home := setupTestHome(t)
+	path := filepath.Join(home, configFile)
+	writeTestConfig(t, path, map[string]string{"EVMR_LEVELS_DIR": home, "EVMR_CREDENTIAL_STORE": CredentialStorePlaintext})
+
+	provider := fakeProvider{AuthResponse{ID: "5", Name: "alice", AccessToken: "access", RefreshToken: "refresh"}}
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Authenticate(config, provider, AuthOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err = LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadCredentials(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.EVMR_ID != "5" || config.EVMR_NAME != "alice" || config.EVMR_TOKEN != "access" || config.EVMR_REFRESH_TOKEN != "refresh" || config.EVMR_AUTH_PROVIDER != "fake" {
+		t.Errorf("stored config = %+v", config)
+	}
+}
+
+func TestGetAuthProvider(t *testing.T) {
+	for name, want := range map[string]string{"discord": "discord", "D": "discord", "gh": "github", "siwe": "ethereum", "wallet": "ethereum"} {
+		provider, err := GetAuthProvider(name)
+		if err != nil || provider.Name() != want {
+			t.Errorf("GetAuthProvider(%q) = %v, %v, want %s", name, provider, err, want)
+		}
+	}
+
+	if _, err := GetAuthProvider("twitter"); err == nil {
+		t.Errorf("GetAuthProvider() accepted an unknown provider")
+	}
+}
+
+type fakeProvider struct {
+	response AuthResponse
+}
+
+func (fakeProvider) Name() string        { return "fake" }
+func (fakeProvider) DisplayName() string { return "Fake" }
+func (fakeProvider) Aliases() []string   { return nil }
+
+func (p fakeProvider) Authenticate(config Config, opts AuthOptions) (AuthResponse, error) {
+	return p.response, nil
+}
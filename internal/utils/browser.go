@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// opens a URL in the user's browser. $BROWSER takes precedence over the platform default,
+// and can be a list of commands separated by ':' where '%s' is replaced by the URL.
+func OpenBrowser(url string) error {
+	if browsers := os.Getenv("BROWSER"); browsers != "" {
+		var lastErr error
+		for _, browser := range strings.Split(browsers, ":") {
+			// skip empty and whitespace-only entries
+			if len(strings.Fields(browser)) == 0 {
+				continue
+			}
+
+			var args []string
+			if strings.Contains(browser, "%s") {
+				args = strings.Fields(strings.ReplaceAll(browser, "%s", url))
+			} else {
+				args = append(strings.Fields(browser), url)
+			}
+
+			if lastErr = exec.Command(args[0], args[1:]...).Start(); lastErr == nil {
+				return nil
+			}
+		}
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no command in $BROWSER")
+		}
+		return fmt.Errorf("failed to run $BROWSER: %v", lastErr)
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		if !HasDisplay() {
+			return fmt.Errorf("no graphical display available")
+		}
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	return cmd.Start()
+}
+
+// reports whether a browser can be opened on this machine, e.g. false in SSH sessions without X forwarding
+func HasDisplay() bool {
+	if os.Getenv("BROWSER") != "" {
+		return true
+	}
+
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return os.Getenv("SSH_CONNECTION") == "" && os.Getenv("SSH_TTY") == ""
+	default:
+		return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+	}
+}
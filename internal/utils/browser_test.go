@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenBrowserSkipsEmptyEntries(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("browser command is a shell script")
+	}
+
+	dir := t.TempDir()
+	opened := filepath.Join(dir, "opened")
+	script := filepath.Join(dir, "browser")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$1\" > "+opened+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("BROWSER", " :\t:"+script)
+	if err := OpenBrowser("https://evmr.sh"); err != nil {
+		t.Fatalf("OpenBrowser() error = %v", err)
+	}
+
+	// the browser is started in the background
+	for i := 0; i < 50; i++ {
+		if data, err := os.ReadFile(opened); err == nil && strings.TrimSpace(string(data)) == "https://evmr.sh" {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Errorf("browser was not opened with the URL")
+}
+
+func TestOpenBrowserOnlyWhitespace(t *testing.T) {
+	t.Setenv("BROWSER", "   ")
+	if err := OpenBrowser("https://evmr.sh"); err == nil {
+		t.Errorf("OpenBrowser() succeeded without a browser command")
+	}
+}
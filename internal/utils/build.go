@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	buildProfileFile = "build.toml"
+)
+
+// returns the compiler args for a level and solution type, read from build.toml in the solutions directory, e.g.
+//
+//	[sol]
+//	args = ["--optimizer-runs", "10000"]
+//
+//	[average.huff]
+//	args = ["--evm-version", "shanghai"]
+//
+// Language profiles apply to every level, level profiles are appended afterwards.
+func LoadBuildProfile(solutionsDir string, level string, solutionType string) ([]string, error) {
+	profilePath := filepath.Join(solutionsDir, buildProfileFile)
+
+	// build profiles are optional
+	if _, err := os.Stat(profilePath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(profilePath)
+	v.SetConfigType("toml")
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("error reading build profile '%s': %v", profilePath, err)
+	}
+
+	var args []string
+	for _, key := range []string{solutionType + ".args", strings.ToLower(level) + "." + solutionType + ".args"} {
+		if !v.IsSet(key) {
+			continue
+		}
+
+		values, ok := v.Get(key).([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid build profile '%s': '%s' must be a list of strings", profilePath, key)
+		}
+
+		for _, value := range values {
+			str, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid build profile '%s': '%s' must be a list of strings", profilePath, key)
+			}
+			args = append(args, str)
+		}
+	}
+
+	return args, nil
+}
+
+// returns the effective compiler args, build profile args first and command line args last
+func GetCompilerArgs(solutionsDir string, level string, solutionType string, compilerArgs string) ([]string, error) {
+	args, err := LoadBuildProfile(solutionsDir, level, solutionType)
+	if err != nil {
+		return nil, err
+	}
+
+	extra, err := splitArgs(compilerArgs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compiler args '%s': %v\n", compilerArgs, err)
+	}
+
+	return append(args, extra...), nil
+}
+
+// splits command line args like a POSIX shell, e.g. `--remappings "a=b c"` into "--remappings" and "a=b c".
+// Single quotes keep everything literally, backslashes escape the next character outside of single quotes.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, c := range s {
+		switch {
+		case escaped:
+			// in double quotes, backslashes only escape characters that are special there
+			if quote == '"' && c != '"' && c != '\\' && c != '$' && c != '`' {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(c)
+			escaped = false
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\\':
+			escaped = true
+			inArg = true
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
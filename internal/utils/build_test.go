@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetCompilerArgsPrecedence(t *testing.T) {
+	solutionsDir := t.TempDir()
+	writeTestFile(t, filepath.Join(solutionsDir, buildProfileFile), `[sol]
+args = ["--optimizer-runs", "10000"]
+
+[huff]
+args = ["--evm-version", "paris"]
+
+[average.huff]
+args = ["--evm-version", "shanghai"]
+`)
+
+	tests := []struct {
+		level        string
+		solutionType string
+		compilerArgs string
+		want         []string
+	}{
+		{"Average", "sol", "", []string{"--optimizer-runs", "10000"}},
+		// level profiles come after language profiles, so the compiler uses their value
+		{"Average", "huff", "", []string{"--evm-version", "paris", "--evm-version", "shanghai"}},
+		{"Sqrt", "huff", "", []string{"--evm-version", "paris"}},
+		// --compiler-args comes last
+		{"Average", "huff", "--evm-version cancun", []string{"--evm-version", "paris", "--evm-version", "shanghai", "--evm-version", "cancun"}},
+		{"Average", "vy", "--optimize gas", []string{"--optimize", "gas"}},
+		{"Average", "yul", "", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := GetCompilerArgs(solutionsDir, tt.level, tt.solutionType, tt.compilerArgs)
+		if err != nil {
+			t.Errorf("GetCompilerArgs(%s, %s, %q) error = %v", tt.level, tt.solutionType, tt.compilerArgs, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetCompilerArgs(%s, %s, %q) = %q, want %q", tt.level, tt.solutionType, tt.compilerArgs, got, tt.want)
+		}
+	}
+}
+
+func TestGetCompilerArgsWithoutProfile(t *testing.T) {
+	got, err := GetCompilerArgs(t.TempDir(), "Average", "sol", "--via-ir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []string{"--via-ir"}) {
+		t.Errorf("GetCompilerArgs() = %q, want [--via-ir]", got)
+	}
+}
+
+func TestLoadBuildProfileInvalid(t *testing.T) {
+	solutionsDir := t.TempDir()
+	writeTestFile(t, filepath.Join(solutionsDir, buildProfileFile), "[sol]\nargs = \"--via-ir\"\n")
+
+	_, err := LoadBuildProfile(solutionsDir, "Average", "sol")
+	if err == nil || !strings.Contains(err.Error(), "'sol.args' must be a list of strings") {
+		t.Errorf("LoadBuildProfile() error = %v, want a list of strings error", err)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		args    string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"  --via-ir  ", []string{"--via-ir"}, false},
+		{"--optimizer-runs 1000", []string{"--optimizer-runs", "1000"}, false},
+		{`--remappings "a=b c"`, []string{"--remappings", "a=b c"}, false},
+		{`--remappings 'a=b c' --via-ir`, []string{"--remappings", "a=b c", "--via-ir"}, false},
+		{`--name=a" "b`, []string{"--name=a b"}, false},
+		{`a\ b`, []string{"a b"}, false},
+		{`"a \"b\" \c"`, []string{`a "b" \c`}, false},
+		{`'a \"b'`, []string{`a \"b`}, false},
+		{`""`, []string{""}, false},
+		{`"a b`, nil, true},
+		{`'a b`, nil, true},
+		{`a\`, nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := splitArgs(tt.args)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("splitArgs(%q) = %q, want an error", tt.args, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitArgs(%q) error = %v", tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
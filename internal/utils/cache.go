@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	cacheDirName = "cache"
+)
+
+// compiler used for each solution type
+var compilers = map[string]string{
+	"sol":  "forge",
+	"yul":  "solc",
+	"vy":   "vyper",
+	"huff": "huffc",
+}
+
+// regular expressions to find local imports for each solution type
+var importRegexes = map[string]*regexp.Regexp{
+	"sol":  regexp.MustCompile(`import\s+(?:[^"';]*\s+from\s+)?["']([^"']+)["']`),
+	"huff": regexp.MustCompile(`#include\s+["']([^"']+)["']`),
+}
+
+// Vyper imports modules instead of files, e.g. 'import interfaces.IERC20 as IERC20' or 'from . import math'
+var vyperImportRegex = regexp.MustCompile(`(?m)^\s*(?:from\s+([\w.]+)\s+)?import\s+([\w.]+)`)
+
+// file extensions of importable Vyper modules and interfaces
+var vyperModuleExtensions = []string{".vy", ".vyi", ".json"}
+
+// returns the cache key of a solution, derived from the source (incl. local imports), compiler, compiler version and flags.
+// Returns an empty key if the compiler version can't be determined, in which case the cache is bypassed.
+func compilationCacheKey(levelsDir string, sourcePath string, solutionType string, contract string, args []string) (string, error) {
+	sourceHash, err := hashSource(levelsDir, sourcePath, solutionType)
+	if err != nil {
+		return "", err
+	}
+
+	compiler := compilers[solutionType]
+	version, err := exec.Command(compiler, "--version").CombinedOutput()
+	if err != nil {
+		return "", nil
+	}
+
+	// forge reads its settings from the foundry config of the levels directory
+	var projectHash string
+	if compiler == "forge" {
+		projectHash = hashFoundryConfig(levelsDir)
+	}
+
+	hasher := sha256.New()
+	for _, part := range []string{sourceHash, compiler, strings.TrimSpace(string(version)), contract, strings.Join(args, "\x00"), projectHash} {
+		hasher.Write([]byte(part))
+		hasher.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// returns the cached bytecode for a cache key, if present
+func readCachedBytecode(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+
+	cacheDir, err := CacheDir()
+	if err != nil {
+		return "", false
+	}
+
+	bytecode, err := os.ReadFile(filepath.Join(cacheDir, key))
+	if err != nil {
+		return "", false
+	}
+
+	return string(bytecode), true
+}
+
+// stores the bytecode for a cache key
+func writeCachedBytecode(key string, bytecode string) error {
+	if key == "" {
+		return nil
+	}
+
+	cacheDir, err := CacheDir()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return fmt.Errorf("error creating cache directory: %v", err)
+	}
+
+	return os.WriteFile(filepath.Join(cacheDir, key), []byte(bytecode), 0644)
+}
+
+// hashes a source file and all local files it imports. Remapped imports are resolved in the levels directory.
+func hashSource(levelsDir string, rootPath string, solutionType string) (string, error) {
+	hasher := sha256.New()
+
+	visited := make(map[string]bool)
+	queue := []string{rootPath}
+
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+
+		if visited[path] {
+			continue
+		}
+		visited[path] = true
+
+		source, err := os.ReadFile(path)
+		if err != nil {
+			// the solution file itself has to exist, imports that can't be resolved locally are ignored
+			if path == rootPath {
+				return "", fmt.Errorf("error reading solution file: %v", err)
+			}
+			continue
+		}
+
+		hasher.Write([]byte(path))
+		hasher.Write(source)
+
+		queue = append(queue, localImports(levelsDir, path, string(source), solutionType)...)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// returns the files imported by a source file that exist locally
+func localImports(levelsDir string, path string, source string, solutionType string) []string {
+	var imports []string
+
+	if solutionType == "vy" {
+		for _, match := range vyperImportRegex.FindAllStringSubmatch(source, -1) {
+			imports = append(imports, resolveVyperImport(levelsDir, path, match[1], match[2])...)
+		}
+		return imports
+	}
+
+	importRegex := importRegexes[solutionType]
+	if importRegex == nil {
+		return nil
+	}
+
+	for _, match := range importRegex.FindAllStringSubmatch(source, -1) {
+		if importPath := resolveImport(levelsDir, path, match[1]); importPath != "" {
+			imports = append(imports, importPath)
+		}
+	}
+
+	return imports
+}
+
+// resolves a Vyper import to the files of the module. 'from a import b' imports either the module a/b or the member b
+// of the module a, so both are returned if they exist. Relative modules are resolved from the importing file,
+// others from the levels directory and the directory of the importing file. Builtin modules like 'vyper.interfaces' don't exist locally.
+func resolveVyperImport(levelsDir string, importingFile string, from string, name string) []string {
+	var modules []string
+	if from == "" {
+		modules = []string{name}
+	} else {
+		modules = []string{strings.TrimSuffix(from, ".") + "." + name, from}
+	}
+
+	var files []string
+	for _, module := range modules {
+		dirs := []string{levelsDir, filepath.Dir(importingFile)}
+
+		// each leading dot after the first one goes up one directory
+		if strings.HasPrefix(module, ".") {
+			dir := filepath.Dir(importingFile)
+			module = strings.TrimPrefix(module, ".")
+			for strings.HasPrefix(module, ".") {
+				dir = filepath.Dir(dir)
+				module = strings.TrimPrefix(module, ".")
+			}
+			dirs = []string{dir}
+		}
+
+		if module == "" {
+			continue
+		}
+
+		for _, dir := range dirs {
+			for _, ext := range vyperModuleExtensions {
+				path := filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(module, ".", "/"))+ext)
+				if fileExists(path) {
+					files = append(files, path)
+				}
+			}
+		}
+	}
+
+	return files
+}
+
+// resolves an import to a file in the levels directory, returns an empty string for remapped imports (e.g. forge-std)
+func resolveImport(levelsDir string, importingFile string, importPath string) string {
+	if strings.HasPrefix(importPath, "./") || strings.HasPrefix(importPath, "../") {
+		return filepath.Join(filepath.Dir(importingFile), importPath)
+	}
+
+	path := filepath.Join(levelsDir, importPath)
+	if fileExists(path) {
+		return path
+	}
+
+	return ""
+}
@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// installs a fake forge, which creates the output directory and counts its builds in the returned file
+func fakeForge(t *testing.T) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("fake forge is a shell script")
+	}
+
+	binDir := t.TempDir()
+	builds := filepath.Join(binDir, "builds")
+	script := "#!/bin/sh\nif [ \"$1\" = \"--version\" ]; then echo 'forge 0.2.0'; exit 0; fi\nmkdir -p \"$4\"\necho build >> " + builds + "\n"
+	if err := os.WriteFile(filepath.Join(binDir, "forge"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return builds
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func countBuilds(t *testing.T, builds string) int {
+	data, err := os.ReadFile(builds)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.Count(string(data), "build")
+}
+
+func TestBuildSolidityRebuildsOnFoundryConfigChange(t *testing.T) {
+	setupTestHome(t)
+	builds := fakeForge(t)
+
+	levelsDir := t.TempDir()
+	solPath := filepath.Join(levelsDir, "src", "Average.sol")
+	writeTestFile(t, solPath, "contract Average {}")
+	writeTestFile(t, filepath.Join(levelsDir, "foundry.toml"), "[profile.default]\noptimizer = false\n")
+
+	build := func() {
+		t.Helper()
+		if _, err := buildSolidity(levelsDir, solPath, "Average", nil); err != nil {
+			t.Fatalf("buildSolidity() error = %v", err)
+		}
+	}
+
+	build()
+	build()
+	if n := countBuilds(t, builds); n != 1 {
+		t.Fatalf("%d builds, an unchanged solution should only be built once", n)
+	}
+
+	writeTestFile(t, filepath.Join(levelsDir, "foundry.toml"), "[profile.default]\noptimizer = true\n")
+	build()
+	if n := countBuilds(t, builds); n != 2 {
+		t.Errorf("%d builds, changing foundry.toml should trigger a build", n)
+	}
+
+	writeTestFile(t, filepath.Join(levelsDir, "remappings.txt"), "forge-std/=lib/forge-std/src/\n")
+	build()
+	if n := countBuilds(t, builds); n != 3 {
+		t.Errorf("%d builds, changing remappings.txt should trigger a build", n)
+	}
+}
+
+func TestCompilationCacheKeyIncludesFoundryConfig(t *testing.T) {
+	setupTestHome(t)
+	fakeForge(t)
+
+	levelsDir := t.TempDir()
+	solPath := filepath.Join(levelsDir, "src", "Average.sol")
+	writeTestFile(t, solPath, "contract Average {}")
+
+	key := func() string {
+		t.Helper()
+		key, err := compilationCacheKey(levelsDir, solPath, "sol", "Average", nil)
+		if err != nil || key == "" {
+			t.Fatalf("compilationCacheKey() = %q, %v", key, err)
+		}
+		return key
+	}
+
+	before := key()
+	if key() != before {
+		t.Fatalf("cache key is not stable")
+	}
+
+	writeTestFile(t, filepath.Join(levelsDir, "remappings.txt"), "solady/=lib/solady/src/\n")
+	if key() == before {
+		t.Errorf("cache key didn't change with remappings.txt")
+	}
+}
+
+func TestHashSourceTracksVyperImports(t *testing.T) {
+	levelsDir := t.TempDir()
+	solution := filepath.Join(levelsDir, "src", "Average.vy")
+	writeTestFile(t, solution, `# @version 0.3.10
+from vyper.interfaces import ERC20
+import interfaces.IAverage as IAverage
+from . import math
+from .lib import helpers
+
+implements: IAverage
+`)
+	imported := []string{
+		filepath.Join(levelsDir, "interfaces", "IAverage.vyi"),
+		filepath.Join(levelsDir, "src", "math.vy"),
+		filepath.Join(levelsDir, "src", "lib", "helpers.vy"),
+	}
+	for _, path := range imported {
+		writeTestFile(t, path, "# module")
+	}
+
+	before, err := hashSource(levelsDir, solution, "vy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range imported {
+		writeTestFile(t, path, "# changed "+path)
+
+		after, err := hashSource(levelsDir, solution, "vy")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if after == before {
+			t.Errorf("hash didn't change with '%s'", path)
+		}
+		before = after
+	}
+}
+
+func TestResolveVyperImport(t *testing.T) {
+	levelsDir := t.TempDir()
+	importing := filepath.Join(levelsDir, "src", "sub", "Solution.vy")
+	writeTestFile(t, filepath.Join(levelsDir, "src", "utils.vy"), "")
+	writeTestFile(t, filepath.Join(levelsDir, "interfaces", "IERC20.json"), "")
+
+	tests := []struct {
+		from, name string
+		want       string
+	}{
+		{"..", "utils", filepath.Join(levelsDir, "src", "utils.vy")},
+		{"", "interfaces.IERC20", filepath.Join(levelsDir, "interfaces", "IERC20.json")},
+		{"interfaces", "IERC20", filepath.Join(levelsDir, "interfaces", "IERC20.json")},
+		{"vyper.interfaces", "ERC20", ""},
+	}
+
+	for _, tt := range tests {
+		files := resolveVyperImport(levelsDir, importing, tt.from, tt.name)
+		if tt.want == "" {
+			if len(files) != 0 {
+				t.Errorf("resolveVyperImport(%q, %q) = %v, want none", tt.from, tt.name, files)
+			}
+			continue
+		}
+		if len(files) != 1 || files[0] != tt.want {
+			t.Errorf("resolveVyperImport(%q, %q) = %v, want %s", tt.from, tt.name, files, tt.want)
+		}
+	}
+}
@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"github.com/spf13/viper"
 	"os"
-	"os/user"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 const (
@@ -21,6 +21,19 @@ type Config struct {
 	EVMR_ID         string `mapstructure:"EVMR_ID"`
 	EVMR_NAME       string `mapstructure:"EVMR_NAME"`
 	EVMR_LEVELS_DIR string `mapstructure:"EVMR_LEVELS_DIR"`
+
+	EVMR_SOLUTIONS_DIR    string `mapstructure:"EVMR_SOLUTIONS_DIR"`
+	EVMR_REFRESH_TOKEN    string `mapstructure:"EVMR_REFRESH_TOKEN"`
+	EVMR_AUTH_PROVIDER    string `mapstructure:"EVMR_AUTH_PROVIDER"`
+	EVMR_CREDENTIAL_STORE string `mapstructure:"EVMR_CREDENTIAL_STORE"`
+	EVMR_CONFIG_VERSION   string `mapstructure:"EVMR_CONFIG_VERSION"`
+	EVMR_THEME            string `mapstructure:"EVMR_THEME"`
+	EVMR_BOX_STYLE        string `mapstructure:"EVMR_BOX_STYLE"`
+
+	// profile the config was loaded for, credentials are loaded from its account in the credential store
+	profile string
+	// set once the credentials were loaded from the credential store, see LoadCredentials
+	credentialsLoaded bool
 }
 
 type Level struct {
@@ -29,117 +42,390 @@ type Level struct {
 	Contract    string
 	Type        string
 	Description string
+	Difficulty  string
+	Tags        []string
+	Author      string
+	// zero if not set
+	ReleaseDate time.Time
+	// languages with a template, all languages if empty
+	Languages []string
+	Hints     []string
+	// name of the level pack
+	Pack string
 }
 
+// returns the path of the config file, e.g. ~/.evm-runners/.env
+func ConfigFilePath() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, configFile), nil
+}
+
+// loads the config of the active profile. Credentials are not loaded until they are needed, see LoadCredentials.
 func LoadConfig() (Config, error) {
-	var config Config
+	profile, err := ActiveProfile()
+	if err != nil {
+		return Config{}, err
+	}
 
-	usr, err := user.Current()
+	config, _, err := loadLayeredConfig(profile)
+	return config, err
+}
+
+// loads the config of the active profile including its credentials and returns where each value came from
+func LoadConfigWithOrigins() (Config, map[string]string, error) {
+	profile, err := ActiveProfile()
 	if err != nil {
-		return config, fmt.Errorf("error getting user's home directory: %v", err)
+		return Config{}, nil, err
 	}
 
-	envFilePath := filepath.Join(usr.HomeDir, ".evm-runners", ".env")
-	viper.SetConfigFile(envFilePath)
+	config, origins, err := loadLayeredConfig(profile)
+	if err != nil {
+		return config, nil, err
+	}
 
-	// Check if the config file exists before trying to read it
-	if _, err := os.Stat(envFilePath); os.IsNotExist(err) {
+	if err := loadCredentials(&config, origins); err != nil {
+		return config, nil, err
+	}
+
+	return config, origins, nil
+}
+
+// loads the config of a profile. Profiles other than the default profile are merged over the default config file.
+func LoadProfileConfig(profile string) (Config, error) {
+	config, _, err := loadLayeredConfig(profile)
+	return config, err
+}
+
+// loads the config of a profile from its config files only, ignoring environment variables and flags,
+// e.g. to show the settings of profiles other than the active one
+func LoadProfileFileConfig(profile string) (Config, error) {
+	config, _, _, err := loadConfigFiles(profile)
+	return config, err
+}
+
+// loads the config of a profile from these layers, each overriding the previous ones:
+// defaults < config file < profile config file < environment variables < flags.
+// Credentials are read from the credential store by LoadCredentials.
+func loadLayeredConfig(profile string) (Config, map[string]string, error) {
+	config, origins, hasConfigFile, err := loadConfigFiles(profile)
+	if err != nil {
+		return config, nil, err
+	}
+
+	// environment variables, NO_COLOR is overridden by EVMR_THEME
+	if noColorSet() {
+		config.EVMR_THEME = ThemeNoColor
+		origins["EVMR_THEME"] = fmt.Sprintf("env (%s)", noColorEnvVar)
+	}
+	for _, key := range ConfigKeys {
+		if value, ok := os.LookupEnv(key.Name); ok && value != "" {
+			config.Set(key.Name, value)
+			origins[key.Name] = fmt.Sprintf("env (%s)", key.Name)
+		}
+	}
+
+	// flags
+	for key, flag := range configFlags {
+		config.Set(key, flag.value)
+		origins[key] = fmt.Sprintf("flag (--%s)", flag.name)
+	}
+
+	if !hasConfigFile && config.EVMR_LEVELS_DIR == "" {
 		// print error to run evm-runners init first
-		return config, fmt.Errorf("No config file found. Please run 'evmr init' first!\n")
+		return config, nil, fmt.Errorf("No config file found. Please run 'evmr init' first!\n")
 	}
 
-	// Read the config file
-	if err := viper.ReadInConfig(); err != nil {
-		return config, fmt.Errorf("error reading in config file: %v", err)
+	return config, origins, nil
+}
+
+// loads the defaults, the config file and the profile config file. Also returns whether the config file exists.
+func loadConfigFiles(profile string) (Config, map[string]string, bool, error) {
+	config := Config{profile: profile}
+	origins := make(map[string]string)
+
+	// defaults
+	for key, value := range configDefaults {
+		config.Set(key, value)
+		origins[key] = "default"
+	}
+
+	envFilePath, err := ConfigFilePath()
+	if err != nil {
+		return config, nil, false, err
+	}
+
+	// config file, which is optional if everything is set via environment variables or flags
+	hasConfigFile := fileExists(envFilePath)
+	if hasConfigFile {
+		if err := migrateConfigFile(envFilePath, DefaultProfile); err != nil {
+			return config, nil, false, err
+		}
+
+		if err := applyEnvFile(&config, origins, envFilePath, "file"); err != nil {
+			return config, nil, false, err
+		}
+
+		// credentials belong to the account of a single profile and are never inherited
+		if profile != DefaultProfile {
+			for _, key := range credentialKeys() {
+				config.Set(key.Name, "")
+				delete(origins, key.Name)
+			}
+		}
 	}
 
-	// Automatically load environment variables
-	viper.AutomaticEnv()
+	// profile config file
+	profilePath, err := ProfileFilePath(profile)
+	if err != nil {
+		return config, nil, false, err
+	}
 
-	// Unmarshal the config into the Config struct
-	if err := viper.Unmarshal(&config); err != nil {
-		return config, fmt.Errorf("error unmarshalling config: %v", err)
+	if profile != DefaultProfile {
+		if !fileExists(profilePath) {
+			return config, nil, false, fmt.Errorf("Profile '%s' does not exist. Run 'evmr profile add %s' to create it.\n", profile, profile)
+		}
+
+		if err := migrateConfigFile(profilePath, profile); err != nil {
+			return config, nil, false, err
+		}
+
+		if err := applyEnvFile(&config, origins, profilePath, "profile"); err != nil {
+			return config, nil, false, err
+		}
 	}
 
-	return config, nil
+	return config, origins, hasConfigFile, nil
 }
 
-func WriteConfig(config Config) error {
-	usr, err := user.Current()
+// loads the credentials of the config from the credential store, unless they are set via environment variables
+// or flags. Credentials are only loaded where they are sent, as the credential store may ask for a passphrase.
+func LoadCredentials(config *Config) error {
+	return loadCredentials(config, make(map[string]string))
+}
+
+func loadCredentials(config *Config, origins map[string]string) error {
+	if config.credentialsLoaded {
+		return nil
+	}
+
+	profile := config.profile
+	if profile == "" {
+		var err error
+		if profile, err = ActiveProfile(); err != nil {
+			return err
+		}
+	}
+
+	store, err := GetCredentialStore(*config)
 	if err != nil {
-		return fmt.Errorf("error getting user's home directory: %v", err)
+		return err
 	}
 
-	envFilePath := filepath.Join(usr.HomeDir, ".evm-runners", ".env")
-	viper.SetConfigFile(envFilePath)
-	viper.SetConfigType("env")
+	profilePath, err := ProfileFilePath(profile)
+	if err != nil {
+		return err
+	}
 
-	// Read the config file
-	if err := viper.ReadInConfig(); err != nil {
-		return fmt.Errorf("error reading in config file: %v", err)
+	if fileExists(profilePath) {
+		if err := migrateCredentials(profilePath, profile, store); err != nil {
+			return err
+		}
 	}
 
-	viper.Set("EVMR_SERVER", config.EVMR_SERVER)
-	viper.Set("EVMR_LEVELS_DIR", config.EVMR_LEVELS_DIR)
-	viper.Set("EVMR_TOKEN", config.EVMR_TOKEN)
-	viper.Set("EVMR_ID", config.EVMR_ID)
-	viper.Set("EVMR_NAME", config.EVMR_NAME)
+	for _, key := range credentialKeys() {
+		// skip the credential store if the credential is set via environment variable or flag
+		_, isFlag := configFlags[key.Name]
+		if isPlaintext(store) || os.Getenv(key.Name) != "" || isFlag {
+			continue
+		}
 
-	if err := viper.WriteConfig(); err != nil {
-		return fmt.Errorf("failed to write config: %v", err)
+		token, err := store.Get(credentialAccount(profile, key.Name))
+		if err != nil {
+			return fmt.Errorf("error loading auth token: %v", err)
+		}
+
+		config.Set(key.Name, token)
+		if token != "" {
+			origins[key.Name] = fmt.Sprintf("credential store (%s)", store.Name())
+		} else {
+			delete(origins, key.Name)
+		}
 	}
 
+	config.credentialsLoaded = true
+
 	return nil
 }
 
-func LoadLevels() (map[string]Level, error) {
-	config, err := LoadConfig()
+// sets the config keys of a config file that are set, and records their origin
+func applyEnvFile(config *Config, origins map[string]string, path string, layer string) error {
+	v, err := readEnvFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("error loading config: %v", err)
+		return err
+	}
 
+	for _, key := range ConfigKeys {
+		if v.IsSet(key.Name) {
+			config.Set(key.Name, v.GetString(key.Name))
+			origins[key.Name] = fmt.Sprintf("%s (%s)", layer, path)
+		}
 	}
 
-	levelsFilePath := filepath.Join(config.EVMR_LEVELS_DIR, levelsFile)
-	viper.SetConfigFile(levelsFilePath)
+	return nil
+}
 
-	// Check if the config file exists before trying to read it
-	if _, err := os.Stat(levelsFilePath); os.IsNotExist(err) {
-		// print error to run evm-runners init first
-		return nil, fmt.Errorf("No config file found. Please run 'evmr init' first!\n")
+// writes the config to the config file of the active profile
+func WriteConfig(config Config) error {
+	profile, err := ActiveProfile()
+	if err != nil {
+		return err
+	}
+
+	envFilePath, err := ProfileFilePath(profile)
+	if err != nil {
+		return err
+	}
+
+	// only changed values are written, so values of other layers, e.g. defaults, the default config file of a profile,
+	// environment variables and flags, don't end up in the file. Loading also migrates the config file, so it has to
+	// happen before the file is read.
+	current, _, err := loadLayeredConfig(profile)
+	if err != nil {
+		current = Config{}
+	} else if config.credentialsLoaded {
+		_ = LoadCredentials(&current)
 	}
 
 	// Read the config file
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("error reading in config file: %v", err)
+	v, err := readEnvFile(envFilePath)
+	if err != nil {
+		return err
 	}
 
-	// Get the levels array from the config file
-	levelsConfig := viper.Get("levels").([]interface{})
+	store, err := GetCredentialStore(config)
+	if err != nil {
+		return err
+	}
 
-	// Create a map of Level structs
-	levels := make(map[string]Level)
+	settings := v.AllSettings()
+	for _, key := range ConfigKeys {
+		if key.ReadOnly {
+			continue
+		}
 
-	// Function to safely convert interface{} to string
-	getStringValue := func(val interface{}, fieldName string) string {
-		if str, ok := val.(string); ok {
-			return str
+		if current.Get(key.Name) == config.Get(key.Name) {
+			continue
 		}
-		fmt.Printf("ERROR: Failed to convert field '%s' to string\nTry running 'evmr init' again!\n\n", fieldName)
-		return "ERROR"
+
+		// credentials are only written to the config file if the plaintext store is selected
+		if key.Credential && !isPlaintext(store) {
+			// credentials that were never loaded are kept as they are
+			if !config.credentialsLoaded && config.Get(key.Name) == "" {
+				continue
+			}
+
+			if err := setCredential(store, credentialAccount(profile, key.Name), config.Get(key.Name)); err != nil {
+				return err
+			}
+			delete(settings, strings.ToLower(key.Name))
+			continue
+		}
+
+		// empty keys are left out, as they would override the values of other layers
+		if value := config.Get(key.Name); value != "" {
+			settings[strings.ToLower(key.Name)] = value
+		} else {
+			delete(settings, strings.ToLower(key.Name))
+		}
+	}
+
+	settings[strings.ToLower(configVersionKey)] = configVersion
+
+	return writeEnvFile(envFilePath, settings)
+}
+
+// sets a value in the config file of a profile
+func setProfileValue(profile string, name string, value string) error {
+	path, err := ProfileFilePath(profile)
+	if err != nil {
+		return err
+	}
+
+	v, err := readEnvFile(path)
+	if err != nil {
+		return err
 	}
 
-	// Loop through each level and create a new Level struct
-	for _, levelConfig := range levelsConfig {
-		l := levelConfig.(map[string]interface{})
-		level := Level{
-			ID:          getStringValue(l["id"], "id"),
-			File:        getStringValue(l["file"], "file"),
-			Contract:    getStringValue(l["contract"], "contract"),
-			Type:        getStringValue(l["type"], "type"),
-			Description: getStringValue(l["description"], "description"),
+	v.Set(name, value)
+	if err := v.WriteConfig(); err != nil {
+		return fmt.Errorf("failed to write config: %v", err)
+	}
+
+	return nil
+}
+
+// overwrites a .env file with the given settings
+func writeEnvFile(path string, settings map[string]interface{}) error {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("env")
+	v.SetConfigPermissions(credentialFileMode)
+
+	for key, value := range settings {
+		v.Set(key, value)
+	}
+
+	if err := v.WriteConfig(); err != nil {
+		return fmt.Errorf("failed to write config: %v", err)
+	}
+
+	return nil
+}
+
+// reads a .env file into a new viper instance
+func readEnvFile(path string) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("env")
+	v.SetConfigPermissions(credentialFileMode)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("error reading in config file: %v", err)
+	}
+
+	return v, nil
+}
+
+// loads the levels of all installed packs, keyed by pack and lowercased contract name, e.g. 'official/average'
+func LoadLevels() (map[string]Level, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading config: %v", err)
+
+	}
+
+	packs, err := LoadPacks(config)
+	if err != nil {
+		return nil, err
+	}
+
+	levels := make(map[string]Level)
+	for _, pack := range packs {
+		packLevels, err := loadPackLevels(pack)
+		if err != nil {
+			if pack.Name == OfficialPack {
+				return nil, err
+			}
+			return nil, fmt.Errorf("error loading level pack '%s': %v", pack.Name, err)
+		}
+
+		for key, level := range packLevels {
+			levels[pack.Name+"/"+key] = level
 		}
-		// Add the new Level struct to the map
-		levels[strings.ToLower(level.Contract)] = level
 	}
 
 	return levels, nil
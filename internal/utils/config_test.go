@@ -0,0 +1,272 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// points all evm-runners directories to a temporary directory and clears the settings of the environment
+func setupTestHome(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv(homeEnvVar, home)
+	t.Setenv("HOME", home)
+	t.Setenv(noColorEnvVar, "")
+	t.Setenv(passphraseEnvVar, "")
+	t.Setenv(profileKey, "")
+	for _, key := range ConfigKeys {
+		t.Setenv(key.Name, "")
+	}
+
+	profileFlag = ""
+	configFlags = make(map[string]configFlag)
+	passphrase = nil
+	fileKey.salt, fileKey.key = nil, nil
+
+	t.Cleanup(func() {
+		profileFlag = ""
+		configFlags = make(map[string]configFlag)
+		passphrase = nil
+		fileKey.salt, fileKey.key = nil, nil
+	})
+
+	return home
+}
+
+// writes a .env file with the current config version and the given settings
+func writeTestConfig(t *testing.T, path string, settings map[string]string) {
+	t.Helper()
+
+	lines := []string{fmt.Sprintf("%s=%d", configVersionKey, configVersion)}
+	for key, value := range settings {
+		lines = append(lines, key+"="+value)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteConfigSkipsEmptyAndDefaultValues(t *testing.T) {
+	home := setupTestHome(t)
+	path := filepath.Join(home, configFile)
+	writeTestConfig(t, path, map[string]string{
+		"EVMR_LEVELS_DIR":       home,
+		"EVMR_NAME":             "alice",
+		"EVMR_CREDENTIAL_STORE": CredentialStorePlaintext,
+	})
+	t.Setenv("EVMR_SERVER", "http://localhost:8080/")
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.EVMR_ID = "5"
+	config.EVMR_NAME = ""
+
+	if err := WriteConfig(config); err != nil {
+		t.Fatalf("WriteConfig() error = %v", err)
+	}
+
+	v, err := readEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.GetString("EVMR_ID"); got != "5" {
+		t.Errorf("EVMR_ID = %q, want 5", got)
+	}
+	for _, name := range []string{"EVMR_NAME", "EVMR_TOKEN", "EVMR_SOLUTIONS_DIR", "EVMR_THEME", "EVMR_BOX_STYLE", "EVMR_SERVER"} {
+		if v.IsSet(name) {
+			t.Errorf("%s = %q was written", name, v.GetString(name))
+		}
+	}
+}
+
+func TestProfilesDontInheritCredentials(t *testing.T) {
+	home := setupTestHome(t)
+	writeTestConfig(t, filepath.Join(home, configFile), map[string]string{
+		"EVMR_LEVELS_DIR":       home,
+		"EVMR_TOKEN":            "default-token",
+		"EVMR_REFRESH_TOKEN":    "default-refresh",
+		"EVMR_CREDENTIAL_STORE": CredentialStorePlaintext,
+	})
+	writeTestConfig(t, filepath.Join(home, profilesDir, "staging"+configFile), nil)
+
+	config, err := LoadProfileConfig("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadCredentials(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.EVMR_TOKEN != "" || config.EVMR_REFRESH_TOKEN != "" {
+		t.Errorf("profile inherited the credentials of the default profile: %q, %q", config.EVMR_TOKEN, config.EVMR_REFRESH_TOKEN)
+	}
+
+	config, err = LoadProfileConfig(DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.EVMR_TOKEN != "default-token" {
+		t.Errorf("EVMR_TOKEN of the default profile = %q", config.EVMR_TOKEN)
+	}
+}
+
+func TestLoadProfileConfigPrecedence(t *testing.T) {
+	home := setupTestHome(t)
+	writeTestConfig(t, filepath.Join(home, configFile), map[string]string{
+		"EVMR_SERVER":     "http://default/",
+		"EVMR_LEVELS_DIR": "/default-levels",
+		"EVMR_NAME":       "alice",
+		"EVMR_THEME":      ThemeLight,
+	})
+	writeTestConfig(t, filepath.Join(home, profilesDir, "staging"+configFile), map[string]string{
+		"EVMR_SERVER": "http://staging/",
+		"EVMR_NAME":   "bob",
+	})
+
+	t.Setenv("EVMR_NAME", "carol")
+	if err := SetConfigFlag("EVMR_THEME", "no-color", ThemeNoColor); err != nil {
+		t.Fatal(err)
+	}
+
+	SetProfile("staging")
+	config, origins, err := loadLayeredConfig("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"EVMR_SERVER":     "http://staging/",
+		"EVMR_LEVELS_DIR": "/default-levels",
+		"EVMR_NAME":       "carol",
+		"EVMR_THEME":      ThemeNoColor,
+		"EVMR_BOX_STYLE":  BoxStyleUnicode,
+	}
+	wantOrigin := map[string]string{
+		"EVMR_SERVER":     "profile",
+		"EVMR_LEVELS_DIR": "file",
+		"EVMR_NAME":       "env",
+		"EVMR_THEME":      "flag",
+		"EVMR_BOX_STYLE":  "default",
+	}
+
+	for name, value := range want {
+		if got := config.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+		if origin := origins[name]; !strings.HasPrefix(origin, wantOrigin[name]) {
+			t.Errorf("origin of %s = %q, want %s", name, origin, wantOrigin[name])
+		}
+	}
+
+	// the default profile isn't affected by the profile config file
+	config, _, err = loadLayeredConfig(DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.EVMR_SERVER != "http://default/" {
+		t.Errorf("EVMR_SERVER of the default profile = %q", config.EVMR_SERVER)
+	}
+}
+
+func TestLoadProfileFileConfigIgnoresOverrides(t *testing.T) {
+	home := setupTestHome(t)
+	writeTestConfig(t, filepath.Join(home, configFile), map[string]string{
+		"EVMR_SERVER":     "http://default/",
+		"EVMR_LEVELS_DIR": "/default-levels",
+	})
+	writeTestConfig(t, filepath.Join(home, profilesDir, "staging"+configFile), map[string]string{
+		"EVMR_SERVER": "http://staging/",
+	})
+
+	flagLevels := t.TempDir()
+	t.Setenv("EVMR_SERVER", "http://env/")
+	if err := SetConfigFlag("EVMR_LEVELS_DIR", "levels-dir", flagLevels); err != nil {
+		t.Fatal(err)
+	}
+
+	for profile, server := range map[string]string{DefaultProfile: "http://default/", "staging": "http://staging/"} {
+		config, err := LoadProfileFileConfig(profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.EVMR_SERVER != server || config.EVMR_LEVELS_DIR != "/default-levels" {
+			t.Errorf("%s: server = %q, levels dir = %q, want the values of the config files", profile, config.EVMR_SERVER, config.EVMR_LEVELS_DIR)
+		}
+	}
+
+	// the active profile still uses the overrides
+	config, err := LoadProfileConfig("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.EVMR_SERVER != "http://env/" || config.EVMR_LEVELS_DIR != flagLevels {
+		t.Errorf("LoadProfileConfig() ignored the environment and flags")
+	}
+}
+
+func TestWriteConfigOnlyWritesProfileValues(t *testing.T) {
+	home := setupTestHome(t)
+	writeTestConfig(t, filepath.Join(home, configFile), map[string]string{
+		"EVMR_SERVER":           "http://default/",
+		"EVMR_LEVELS_DIR":       "/default-levels",
+		"EVMR_CREDENTIAL_STORE": CredentialStorePlaintext,
+	})
+	profilePath := filepath.Join(home, profilesDir, "staging"+configFile)
+	writeTestConfig(t, profilePath, map[string]string{"EVMR_NAME": "bob"})
+
+	SetProfile("staging")
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.EVMR_ID = "7"
+
+	if err := WriteConfig(config); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := readEnvFile(profilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.GetString("EVMR_ID") != "7" || v.GetString("EVMR_NAME") != "bob" {
+		t.Errorf("profile config = %v", v.AllSettings())
+	}
+	for _, name := range []string{"EVMR_SERVER", "EVMR_LEVELS_DIR", "EVMR_CREDENTIAL_STORE"} {
+		if v.IsSet(name) {
+			t.Errorf("inherited %s was written to the profile config file", name)
+		}
+	}
+}
+
+func TestCredentialStoreFromEnvironment(t *testing.T) {
+	home := setupTestHome(t)
+	writeTestConfig(t, filepath.Join(home, configFile), map[string]string{
+		"EVMR_LEVELS_DIR":       home,
+		"EVMR_TOKEN":            "plaintext-token",
+		"EVMR_CREDENTIAL_STORE": CredentialStoreFile,
+	})
+	t.Setenv("EVMR_CREDENTIAL_STORE", CredentialStorePlaintext)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the file store would ask for a passphrase and move the token out of the config file
+	if err := LoadCredentials(&config); err != nil {
+		t.Fatalf("LoadCredentials() error = %v", err)
+	}
+	if config.EVMR_TOKEN != "plaintext-token" {
+		t.Errorf("EVMR_TOKEN = %q, want the token of the config file", config.EVMR_TOKEN)
+	}
+}
@@ -0,0 +1,311 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+type ConfigKey struct {
+	Name        string
+	Description string
+	// secret values are masked when listed
+	Secret bool
+	// read-only values are managed by evm-runners and can't be set
+	ReadOnly bool
+	// credentials are kept in the credential store instead of the config file
+	Credential bool
+	// validates and normalizes a value before it is written
+	Validate func(value string) (string, error)
+}
+
+// all known config keys. To add a new key, add a field with a mapstructure tag to Config and an entry here.
+var ConfigKeys = []ConfigKey{
+	{Name: "EVMR_SERVER", Description: "URL of the evm-runners server", Validate: validateServerURL},
+	{Name: "EVMR_LEVELS_DIR", Description: "Directory of the evm-runners levels", Validate: validateLevelsDir},
+	{Name: "EVMR_SOLUTIONS_DIR", Description: "Directory of your solutions, defaults to the levels directory with a '-solutions' suffix", Validate: validateSolutionsDir},
+	{Name: "EVMR_TOKEN", Description: "Authentication token", Secret: true, Credential: true},
+	{Name: "EVMR_REFRESH_TOKEN", Description: "Token used to renew the authentication token", Secret: true, Credential: true},
+	{Name: "EVMR_AUTH_PROVIDER", Description: "Platform used to authenticate: discord, github or ethereum", Validate: validateAuthProvider},
+	{Name: "EVMR_ID", Description: "User ID"},
+	{Name: "EVMR_NAME", Description: "User name"},
+	{Name: "EVMR_VERSION", Description: "Installed evm-runners version", ReadOnly: true},
+	{Name: "EVMR_CREDENTIAL_STORE", Description: "Where the auth token is stored: keyring, file or plaintext", Validate: validateCredentialStore},
+	{Name: "EVMR_CONFIG_VERSION", Description: "Version of the config file format", ReadOnly: true},
+	{Name: "EVMR_THEME", Description: "Colors of the output: dark, light, high-contrast or no-color", Validate: validateTheme},
+	{Name: "EVMR_BOX_STYLE", Description: "Characters boxes are drawn with: unicode or ascii", Validate: validateBoxStyle},
+}
+
+const (
+	DefaultServer = "https://api.evmr.sh/"
+)
+
+// default values of config keys
+var configDefaults = map[string]string{
+	"EVMR_SERVER":    DefaultServer,
+	"EVMR_THEME":     ThemeDark,
+	"EVMR_BOX_STYLE": BoxStyleUnicode,
+}
+
+type configFlag struct {
+	name  string
+	value string
+}
+
+// config keys overridden with command line flags
+var configFlags = make(map[string]configFlag)
+
+// overrides a config key with the value of a command line flag, e.g. --server
+func SetConfigFlag(name string, flagName string, value string) error {
+	key, err := GetConfigKey(name)
+	if err != nil {
+		return err
+	}
+
+	if key.Validate != nil {
+		value, err = key.Validate(value)
+		if err != nil {
+			return err
+		}
+	}
+
+	configFlags[key.Name] = configFlag{name: flagName, value: value}
+
+	return nil
+}
+
+// returns the config key with the given name
+func GetConfigKey(name string) (ConfigKey, error) {
+	name = strings.ToUpper(name)
+	for _, key := range ConfigKeys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+
+	var names []string
+	for _, key := range ConfigKeys {
+		names = append(names, key.Name)
+	}
+
+	return ConfigKey{}, fmt.Errorf("Unknown config key '%s'. Valid keys are: %s\n", name, strings.Join(names, ", "))
+}
+
+// returns the value of a config key
+func (c Config) Get(name string) string {
+	field := configField(&c, name)
+	if !field.IsValid() {
+		return ""
+	}
+
+	return field.String()
+}
+
+// sets the value of a config key
+func (c *Config) Set(name string, value string) {
+	field := configField(c, name)
+	if field.IsValid() {
+		field.SetString(value)
+	}
+}
+
+// returns the struct field of a config key, matched by its mapstructure tag
+func configField(c *Config, name string) reflect.Value {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		if strings.EqualFold(t.Field(i).Tag.Get("mapstructure"), name) {
+			return v.Field(i)
+		}
+	}
+
+	return reflect.Value{}
+}
+
+// returns a value of a config key for display, masking secrets
+func (k ConfigKey) Display(value string) string {
+	if !k.Secret || value == "" {
+		return value
+	}
+
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+
+	return value[:4] + strings.Repeat("*", 8)
+}
+
+// sets a config key in the config file of the active profile
+func SetConfigValue(name string, value string) (string, error) {
+	key, err := GetConfigKey(name)
+	if err != nil {
+		return "", err
+	}
+
+	if key.ReadOnly {
+		return "", fmt.Errorf("'%s' is managed by evm-runners and can't be set.\n", key.Name)
+	}
+
+	if key.Validate != nil {
+		value, err = key.Validate(value)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	path, err := ActiveConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+
+	if key.Credential {
+		handled, err := setActiveCredential(key.Name, value)
+		if err != nil || handled {
+			return value, err
+		}
+	}
+
+	v, err := readEnvFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	v.Set(key.Name, value)
+	if err := v.WriteConfig(); err != nil {
+		return "", fmt.Errorf("failed to write config: %v", err)
+	}
+
+	return value, nil
+}
+
+// removes a config key from the config file of the active profile
+func UnsetConfigValue(name string) error {
+	key, err := GetConfigKey(name)
+	if err != nil {
+		return err
+	}
+
+	if key.ReadOnly {
+		return fmt.Errorf("'%s' is managed by evm-runners and can't be unset.\n", key.Name)
+	}
+
+	path, err := ActiveConfigFilePath()
+	if err != nil {
+		return err
+	}
+
+	if key.Credential {
+		handled, err := setActiveCredential(key.Name, "")
+		if err != nil || handled {
+			return err
+		}
+	}
+
+	v, err := readEnvFile(path)
+	if err != nil {
+		return err
+	}
+
+	// viper can't delete keys, so the file is rewritten without the key
+	settings := v.AllSettings()
+	delete(settings, strings.ToLower(key.Name))
+
+	return writeEnvFile(path, settings)
+}
+
+// stores a credential of the active profile in the credential store.
+// Returns false if the plaintext store is selected, in which case the credential belongs in the config file.
+func setActiveCredential(name string, value string) (bool, error) {
+	profile, err := ActiveProfile()
+	if err != nil {
+		return false, err
+	}
+
+	config, err := LoadProfileConfig(profile)
+	if err != nil {
+		return false, err
+	}
+
+	store, err := GetCredentialStore(config)
+	if err != nil {
+		return false, err
+	}
+
+	if isPlaintext(store) {
+		return false, nil
+	}
+
+	return true, setCredential(store, credentialAccount(profile, name), value)
+}
+
+// returns the config file of the active profile
+func ActiveConfigFilePath() (string, error) {
+	profile, err := ActiveProfile()
+	if err != nil {
+		return "", err
+	}
+
+	path, err := ProfileFilePath(profile)
+	if err != nil {
+		return "", err
+	}
+
+	if !fileExists(path) {
+		if profile == DefaultProfile {
+			return "", fmt.Errorf("No config file found. Please run 'evmr init' first!\n")
+		}
+		return "", fmt.Errorf("Profile '%s' does not exist. Run 'evmr profile add %s' to create it.\n", profile, profile)
+	}
+
+	return path, nil
+}
+
+func validateServerURL(value string) (string, error) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("Invalid server URL '%s'. It has to start with http:// or https://\n", value)
+	}
+
+	// all requests are built by appending the endpoint to the server URL
+	if !strings.HasSuffix(value, "/") {
+		value += "/"
+	}
+
+	return value, nil
+}
+
+func validateLevelsDir(value string) (string, error) {
+	path, err := filepath.Abs(value)
+	if err != nil {
+		return "", fmt.Errorf("error getting absolute path for '%s': %v", value, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("Levels directory '%s' does not exist.\n", path)
+	}
+
+	return path, nil
+}
+
+func validateSolutionsDir(value string) (string, error) {
+	path, err := filepath.Abs(value)
+	if err != nil {
+		return "", fmt.Errorf("error getting absolute path for '%s': %v", value, err)
+	}
+
+	return path, nil
+}
+
+func validateAuthProvider(value string) (string, error) {
+	provider, err := GetAuthProvider(value)
+	if err != nil {
+		return "", err
+	}
+
+	return provider.Name(), nil
+}
@@ -0,0 +1,526 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+const (
+	CredentialStoreKeyring   = "keyring"
+	CredentialStoreFile      = "file"
+	CredentialStorePlaintext = "plaintext"
+
+	credentialsFile    = "credentials"
+	keyringService     = "evm-runners"
+	passphraseEnvVar   = "EVMR_PASSPHRASE"
+	credentialFileMode = 0600
+)
+
+// stores credentials like auth tokens by account, see credentialAccount
+type CredentialStore interface {
+	// returns a description of the backend for messages, e.g. "OS keyring"
+	Name() string
+	// returns the token of an account, or an empty string if none is stored
+	Get(account string) (string, error)
+	Set(account string, token string) error
+	Delete(account string) error
+}
+
+// returns the account a credential of a profile is stored under. The auth token is stored under the
+// profile name, other credentials under "<profile>:<key>", e.g. "default:refresh_token".
+func credentialAccount(profile string, name string) string {
+	if name == "EVMR_TOKEN" {
+		return profile
+	}
+
+	return profile + ":" + strings.ToLower(strings.TrimPrefix(name, "EVMR_"))
+}
+
+// returns the profile and config key of an account
+func parseCredentialAccount(account string) (string, string) {
+	profile, key, found := strings.Cut(account, ":")
+	if !found {
+		return profile, "EVMR_TOKEN"
+	}
+
+	return profile, "EVMR_" + strings.ToUpper(key)
+}
+
+// returns the config keys that are stored in the credential store
+func credentialKeys() []ConfigKey {
+	var keys []ConfigKey
+	for _, key := range ConfigKeys {
+		if key.Credential {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// returns the credential store selected in the config. Without a selection, the OS keyring is used if available,
+// and the encrypted file otherwise. Tokens are only stored in plaintext if explicitly selected.
+func GetCredentialStore(config Config) (CredentialStore, error) {
+	switch config.EVMR_CREDENTIAL_STORE {
+	case CredentialStoreKeyring:
+		if !keyringAvailable() {
+			return nil, fmt.Errorf("The OS keyring is not available. Run 'evmr config set EVMR_CREDENTIAL_STORE file' to use an encrypted file instead.\n")
+		}
+		return keyringStore{}, nil
+	case CredentialStoreFile:
+		return fileStore{}, nil
+	case CredentialStorePlaintext:
+		return plaintextStore{}, nil
+	case "":
+		if keyringAvailable() {
+			return keyringStore{}, nil
+		}
+		return fileStore{}, nil
+	}
+
+	return nil, fmt.Errorf("Invalid credential store '%s'. Use either 'keyring', 'file' or 'plaintext'.\n", config.EVMR_CREDENTIAL_STORE)
+}
+
+func validateCredentialStore(value string) (string, error) {
+	value = strings.ToLower(value)
+	switch value {
+	case CredentialStoreKeyring, CredentialStoreFile, CredentialStorePlaintext:
+		return value, nil
+	}
+
+	return "", fmt.Errorf("Invalid credential store '%s'. Use either 'keyring', 'file' or 'plaintext'.\n", value)
+}
+
+// moves plaintext credentials from a config file into the credential store and restricts the file permissions
+func migrateCredentials(path string, profile string, store CredentialStore) error {
+	if err := os.Chmod(path, credentialFileMode); err != nil {
+		return fmt.Errorf("error changing permissions of '%s': %v", path, err)
+	}
+
+	if isPlaintext(store) {
+		return nil
+	}
+
+	v, err := readEnvFile(path)
+	if err != nil {
+		return err
+	}
+
+	settings := v.AllSettings()
+	moved := false
+	for _, key := range credentialKeys() {
+		token := v.GetString(key.Name)
+		if token == "" {
+			continue
+		}
+
+		if err := store.Set(credentialAccount(profile, key.Name), token); err != nil {
+			return fmt.Errorf("error moving %s to %s: %v", key.Name, store.Name(), err)
+		}
+
+		delete(settings, strings.ToLower(key.Name))
+		moved = true
+	}
+
+	if !moved {
+		return nil
+	}
+
+	if err := writeEnvFile(path, settings); err != nil {
+		return err
+	}
+
+	fmt.Printf("Moved auth token from '%s' to the %s.\n\n", path, store.Name())
+
+	return nil
+}
+
+// stores tokens in the Secret Service (Linux) or the Keychain (macOS)
+type keyringStore struct{}
+
+func keyringAvailable() bool {
+	switch runtime.GOOS {
+	case "linux":
+		// secret-tool needs a D-Bus session, which usually isn't available over SSH
+		_, err := exec.LookPath("secret-tool")
+		return err == nil && os.Getenv("DBUS_SESSION_BUS_ADDRESS") != ""
+	case "darwin":
+		_, err := exec.LookPath("security")
+		return err == nil
+	}
+
+	return false
+}
+
+func (keyringStore) Name() string {
+	return "OS keyring"
+}
+
+func (keyringStore) Get(account string) (string, error) {
+	var execCmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		execCmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", account, "-w")
+	} else {
+		execCmd = exec.Command("secret-tool", "lookup", "service", keyringService, "profile", account)
+	}
+
+	var stderr bytes.Buffer
+	execCmd.Stderr = &stderr
+
+	output, err := execCmd.Output()
+	if err != nil {
+		if keyringNotFound(err, stderr.String()) {
+			return "", nil
+		}
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// reports whether a lookup failed because no token is stored. security exits with 44 (errSecItemNotFound),
+// secret-tool exits with 1 without printing an error.
+func keyringNotFound(err error, stderr string) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+
+	if runtime.GOOS == "darwin" {
+		return exitErr.ExitCode() == 44
+	}
+
+	return exitErr.ExitCode() == 1 && strings.TrimSpace(stderr) == ""
+}
+
+func (keyringStore) Set(account string, token string) error {
+	var execCmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		// the token is passed as a command on stdin, so it doesn't show up in the process list
+		execCmd = exec.Command("security", "-i")
+		execCmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+			securityQuote(keyringService), securityQuote(account), securityQuote(token)))
+	} else {
+		execCmd = exec.Command("secret-tool", "store", "--label", fmt.Sprintf("evm-runners (%s)", account), "service", keyringService, "profile", account)
+		execCmd.Stdin = strings.NewReader(token)
+	}
+
+	if output, err := execCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", err, output)
+	}
+
+	return nil
+}
+
+// quotes an argument of a command of 'security -i'
+func securityQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+func (keyringStore) Delete(account string) error {
+	var execCmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		execCmd = exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", account)
+	} else {
+		execCmd = exec.Command("secret-tool", "clear", "service", keyringService, "profile", account)
+	}
+
+	// deleting a token that doesn't exist is not an error
+	_ = execCmd.Run()
+
+	return nil
+}
+
+// stores tokens in a file encrypted with a passphrase, e.g. ~/.evm-runners/credentials
+type fileStore struct{}
+
+// passphrase of the credentials file, only asked for once per run
+var passphrase []byte
+
+// key derived from the passphrase and the salt of the credentials file, only derived once per run
+var fileKey struct {
+	salt []byte
+	key  *[32]byte
+}
+
+func (fileStore) Name() string {
+	return "encrypted file"
+}
+
+func (s fileStore) Get(account string) (string, error) {
+	tokens, err := s.read()
+	if err != nil {
+		return "", err
+	}
+
+	return tokens[account], nil
+}
+
+func (s fileStore) Set(account string, token string) error {
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	tokens[account] = token
+	return s.write(tokens)
+}
+
+func (s fileStore) Delete(account string) error {
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := tokens[account]; !ok {
+		return nil
+	}
+
+	delete(tokens, account)
+	return s.write(tokens)
+}
+
+func credentialsFilePath() (string, error) {
+	dataDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, credentialsFile), nil
+}
+
+// reads and decrypts the tokens of all profiles. The file layout is salt (16 bytes) | nonce (24 bytes) | secretbox.
+func (fileStore) read() (map[string]string, error) {
+	tokens := make(map[string]string)
+
+	path, err := credentialsFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading credentials file: %v", err)
+	}
+
+	if len(data) < 16+24 {
+		return nil, fmt.Errorf("credentials file '%s' is corrupted", path)
+	}
+
+	pass, err := getPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := cachedKey(pass, data[:16])
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce [24]byte
+	copy(nonce[:], data[16:40])
+
+	plaintext, ok := secretbox.Open(nil, data[40:], &nonce, key)
+	if !ok {
+		// forget the wrong passphrase, so that the next attempt asks again
+		passphrase = nil
+		fileKey.salt, fileKey.key = nil, nil
+		return nil, fmt.Errorf("wrong passphrase for credentials file '%s'", path)
+	}
+
+	if err := json.Unmarshal(plaintext, &tokens); err != nil {
+		return nil, fmt.Errorf("error parsing credentials file: %v", err)
+	}
+
+	return tokens, nil
+}
+
+func (fileStore) write(tokens map[string]string) error {
+	path, err := credentialsFilePath()
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(tokens)
+	if err != nil {
+		return fmt.Errorf("error encoding credentials: %v", err)
+	}
+
+	// a new file needs a confirmed passphrase
+	pass, err := getPassphrase(!fileExists(path))
+	if err != nil {
+		return err
+	}
+
+	// the salt of the file is kept, so the key doesn't have to be derived again. The nonce is new for every write.
+	salt := fileKey.salt
+	if salt == nil {
+		salt = make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("error generating salt: %v", err)
+		}
+	}
+
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return fmt.Errorf("error generating nonce: %v", err)
+	}
+
+	key, err := cachedKey(pass, salt)
+	if err != nil {
+		return err
+	}
+
+	data := append(append([]byte(nil), salt...), nonce[:]...)
+	data = secretbox.Seal(data, plaintext, &nonce, key)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating directory %s: %v", filepath.Dir(path), err)
+	}
+
+	if err := os.WriteFile(path, data, credentialFileMode); err != nil {
+		return fmt.Errorf("error writing credentials file: %v", err)
+	}
+
+	return nil
+}
+
+// returns the key of the credentials file, which is derived with scrypt only if the salt changed
+func cachedKey(pass []byte, salt []byte) (*[32]byte, error) {
+	if fileKey.key != nil && bytes.Equal(fileKey.salt, salt) {
+		return fileKey.key, nil
+	}
+
+	key, err := deriveKey(pass, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	fileKey.salt = append([]byte(nil), salt...)
+	fileKey.key = key
+
+	return key, nil
+}
+
+func deriveKey(pass []byte, salt []byte) (*[32]byte, error) {
+	derived, err := scrypt.Key(pass, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving key: %v", err)
+	}
+
+	var key [32]byte
+	copy(key[:], derived)
+
+	return &key, nil
+}
+
+// returns the passphrase of the credentials file from EVMR_PASSPHRASE, or asks for it
+func getPassphrase(confirm bool) ([]byte, error) {
+	if passphrase != nil {
+		return passphrase, nil
+	}
+
+	if env := os.Getenv(passphraseEnvVar); env != "" {
+		passphrase = []byte(env)
+		return passphrase, nil
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("a passphrase is needed to access your credentials, set %s", passphraseEnvVar)
+	}
+
+	fmt.Printf("Passphrase for your evm-runners credentials: ")
+	pass, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return nil, fmt.Errorf("error reading passphrase: %v", err)
+	}
+
+	if len(pass) == 0 {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+
+	if confirm {
+		fmt.Printf("Confirm passphrase: ")
+		confirmation, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		if err != nil {
+			return nil, fmt.Errorf("error reading passphrase: %v", err)
+		}
+
+		if !bytes.Equal(pass, confirmation) {
+			return nil, fmt.Errorf("passphrases don't match")
+		}
+	}
+
+	passphrase = pass
+	return passphrase, nil
+}
+
+// stores tokens in plaintext in the config file, only used when explicitly selected
+type plaintextStore struct{}
+
+func (plaintextStore) Name() string {
+	return "plaintext config file"
+}
+
+func isPlaintext(store CredentialStore) bool {
+	_, ok := store.(plaintextStore)
+	return ok
+}
+
+func (plaintextStore) Get(account string) (string, error) {
+	profile, name := parseCredentialAccount(account)
+
+	path, err := ProfileFilePath(profile)
+	if err != nil {
+		return "", err
+	}
+
+	v, err := readEnvFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return v.GetString(name), nil
+}
+
+func (plaintextStore) Set(account string, token string) error {
+	profile, name := parseCredentialAccount(account)
+	return setProfileValue(profile, name, token)
+}
+
+func (plaintextStore) Delete(account string) error {
+	profile, name := parseCredentialAccount(account)
+	return setProfileValue(profile, name, "")
+}
+
+// stores a token, or deletes it if the token is empty
+func setCredential(store CredentialStore, account string, token string) error {
+	if token == "" {
+		return store.Delete(account)
+	}
+
+	if err := store.Set(account, token); err != nil {
+		return fmt.Errorf("error storing auth token in %s: %v", store.Name(), err)
+	}
+
+	return nil
+}
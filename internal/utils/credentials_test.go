@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSecurityQuote(t *testing.T) {
+	tests := map[string]string{
+		"default":           `"default"`,
+		"eyJhbGci.eyJz.sig": `"eyJhbGci.eyJz.sig"`,
+		`a "b" c`:           `"a \"b\" c"`,
+		`back\slash`:        `"back\\slash"`,
+	}
+
+	for in, want := range tests {
+		if got := securityQuote(in); got != want {
+			t.Errorf("securityQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestKeyringNotFound(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("exit codes of secret-tool")
+	}
+
+	exitErr := exec.Command("sh", "-c", "exit 1").Run()
+	if !keyringNotFound(exitErr, "") {
+		t.Errorf("exit status 1 without output is not reported as not found")
+	}
+	if keyringNotFound(exitErr, "Cannot autolaunch D-Bus without X11 $DISPLAY") {
+		t.Errorf("exit status 1 with an error message is reported as not found")
+	}
+
+	otherErr := exec.Command("sh", "-c", "exit 2").Run()
+	if keyringNotFound(otherErr, "") {
+		t.Errorf("exit status 2 is reported as not found")
+	}
+
+	if keyringNotFound(errors.New("executable file not found in $PATH"), "") {
+		t.Errorf("a missing executable is reported as not found")
+	}
+}
+
+func TestLoadConfigDoesNotReadCredentialStore(t *testing.T) {
+	home := setupTestHome(t)
+	writeTestConfig(t, filepath.Join(home, configFile), map[string]string{
+		"EVMR_LEVELS_DIR":       home,
+		"EVMR_CREDENTIAL_STORE": CredentialStoreFile,
+	})
+
+	t.Setenv(passphraseEnvVar, "secret")
+	if err := (fileStore{}).Set(DefaultProfile, "stored-token"); err != nil {
+		t.Fatal(err)
+	}
+
+	// without a passphrase, reading the credentials file fails
+	t.Setenv(passphraseEnvVar, "")
+	passphrase = nil
+	fileKey.salt, fileKey.key = nil, nil
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.EVMR_TOKEN != "" {
+		t.Errorf("EVMR_TOKEN = %q before the credentials were loaded", config.EVMR_TOKEN)
+	}
+
+	if err := LoadCredentials(&config); err == nil {
+		t.Errorf("LoadCredentials() succeeded without a passphrase")
+	}
+
+	t.Setenv(passphraseEnvVar, "secret")
+	if err := LoadCredentials(&config); err != nil {
+		t.Fatalf("LoadCredentials() error = %v", err)
+	}
+	if config.EVMR_TOKEN != "stored-token" {
+		t.Errorf("EVMR_TOKEN = %q, want %q", config.EVMR_TOKEN, "stored-token")
+	}
+}
+
+func TestLoadCredentialsPrefersEnvironment(t *testing.T) {
+	home := setupTestHome(t)
+	writeTestConfig(t, filepath.Join(home, configFile), map[string]string{
+		"EVMR_LEVELS_DIR":       home,
+		"EVMR_CREDENTIAL_STORE": CredentialStoreFile,
+	})
+
+	t.Setenv(passphraseEnvVar, "secret")
+	if err := (fileStore{}).Set(DefaultProfile, "stored-token"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EVMR_TOKEN", "env-token")
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadCredentials(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.EVMR_TOKEN != "env-token" {
+		t.Errorf("EVMR_TOKEN = %q, want the environment variable", config.EVMR_TOKEN)
+	}
+}
+
+func TestFileStoreDerivesKeyOnce(t *testing.T) {
+	setupTestHome(t)
+	t.Setenv(passphraseEnvVar, "secret")
+
+	store := fileStore{}
+	if err := store.Set("default", "token"); err != nil {
+		t.Fatal(err)
+	}
+	key := fileKey.key
+
+	if err := store.Set("default:refresh_token", "refresh"); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := store.Get("default"); err != nil || token != "token" {
+		t.Fatalf("Get() = %q, %v", token, err)
+	}
+	if fileKey.key != key {
+		t.Errorf("the key was derived again")
+	}
+
+	// a new run derives the key from the file
+	passphrase = nil
+	fileKey.salt, fileKey.key = nil, nil
+	if token, err := store.Get("default:refresh_token"); err != nil || token != "refresh" {
+		t.Fatalf("Get() = %q, %v", token, err)
+	}
+
+	// a wrong passphrase is rejected
+	passphrase = nil
+	fileKey.salt, fileKey.key = nil, nil
+	t.Setenv(passphraseEnvVar, "wrong")
+	if _, err := store.Get("default"); err == nil {
+		t.Errorf("Get() succeeded with a wrong passphrase")
+	}
+}
+
+func TestPlaintextStore(t *testing.T) {
+	home := setupTestHome(t)
+	path := filepath.Join(home, configFile)
+	writeTestConfig(t, path, map[string]string{"EVMR_LEVELS_DIR": home})
+
+	store := plaintextStore{}
+	if err := store.Set(credentialAccount(DefaultProfile, "EVMR_REFRESH_TOKEN"), "refresh"); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := readEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.GetString("EVMR_REFRESH_TOKEN"); got != "refresh" {
+		t.Errorf("EVMR_REFRESH_TOKEN in config file = %q", got)
+	}
+
+	if token, err := store.Get("default:refresh_token"); err != nil || token != "refresh" {
+		t.Errorf("Get() = %q, %v", token, err)
+	}
+}
+
+func TestCredentialAccount(t *testing.T) {
+	for _, name := range []string{"EVMR_TOKEN", "EVMR_REFRESH_TOKEN"} {
+		account := credentialAccount("staging", name)
+		profile, key := parseCredentialAccount(account)
+		if profile != "staging" || key != name {
+			t.Errorf("parseCredentialAccount(%q) = %q, %q", account, profile, key)
+		}
+	}
+
+	if account := credentialAccount("default", "EVMR_TOKEN"); account != "default" {
+		t.Errorf("credentialAccount() = %q, the token is stored under the profile name", account)
+	}
+}
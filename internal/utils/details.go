@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	testDir = "test"
+	// number of submissions shown of each leaderboard
+	topScores = 3
+)
+
+var (
+	interfaceRegex = regexp.MustCompile(`\binterface\s+\w+[^{]*\{`)
+	functionRegex  = regexp.MustCompile(`\bfunction\s+\w+\s*\([^)]*\)[^;{}]*;`)
+)
+
+// details of a level shown by 'evmr level show' and the level list
+type LevelDetails struct {
+	// function signatures of the interface the solution has to implement
+	Interface []string
+	Solves    string
+	// the user's submission, nil if the user hasn't solved the level or isn't authenticated
+	Best    *SubmissionData
+	TopGas  []SubmissionData
+	TopSize []SubmissionData
+}
+
+// Returns the details of a level. Everything that can't be fetched from the server is left empty.
+func GetLevelDetails(config Config, level Level) LevelDetails {
+	var details LevelDetails
+
+	// use the levels directory and server of the level's pack
+	config, err := PackConfig(config, level.Pack)
+	if err != nil {
+		return details
+	}
+
+	details.Interface, _ = TestInterface(config.EVMR_LEVELS_DIR, level)
+
+	client := &http.Client{
+		Timeout: 1 * time.Second,
+	}
+	details.Solves = strings.TrimSpace(fetchSolves(client, config.EVMR_SERVER, level.ID))
+
+	// the token is only needed for the user's scores, so errors of the credential store are ignored
+	_ = LoadCredentials(&config)
+	if config.EVMR_TOKEN != "" {
+		submissions, _ := FetchSubmissionData(&config)
+		for i, item := range submissions {
+			if strings.EqualFold(item.LevelName, level.Contract) {
+				details.Best = &submissions[i]
+			}
+		}
+	}
+
+	if leaderboard, err := FetchLeaderboard(config.EVMR_SERVER, "gas", level.ID); err == nil {
+		details.TopGas = leaderboard[:minInt(len(leaderboard), topScores)]
+	}
+	if leaderboard, err := FetchLeaderboard(config.EVMR_SERVER, "size", level.ID); err == nil {
+		details.TopSize = leaderboard[:minInt(len(leaderboard), topScores)]
+	}
+
+	return details
+}
+
+// returns the function signatures of the interfaces in the test contract of a level, e.g.
+// 'function getAverage(uint256[] calldata a) external pure returns (uint256)'
+func TestInterface(levelsDir string, level Level) ([]string, error) {
+	path, err := testFile(levelsDir, level)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	source := string(content)
+
+	var signatures []string
+	seen := make(map[string]bool)
+
+	for _, loc := range interfaceRegex.FindAllStringIndex(source, -1) {
+		body := source[loc[1]:]
+
+		// cut the interface at its closing brace
+		depth := 1
+		for i, c := range body {
+			if c == '{' {
+				depth++
+			} else if c == '}' {
+				depth--
+			}
+			if depth == 0 {
+				body = body[:i]
+				break
+			}
+		}
+
+		for _, function := range functionRegex.FindAllString(body, -1) {
+			signature := strings.TrimSuffix(strings.Join(strings.Fields(function), " "), ";")
+			if !seen[signature] {
+				seen[signature] = true
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+
+	return signatures, nil
+}
+
+// returns the test file of a level, e.g. test/Average.t.sol, or the file in test/ that contains the test contract
+func testFile(levelsDir string, level Level) (string, error) {
+	path := filepath.Join(levelsDir, testDir, level.File+".t.sol")
+	if fileExists(path) {
+		return path, nil
+	}
+
+	testContract := "contract " + level.Contract + "TestBase"
+
+	found := ""
+	err := filepath.WalkDir(filepath.Join(levelsDir, testDir), func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".sol") || found != "" {
+			return err
+		}
+
+		content, err := os.ReadFile(p)
+		if err == nil && strings.Contains(string(content), testContract) {
+			found = p
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if found == "" {
+		return "", fmt.Errorf("no test file found for level '%s'", level.Contract)
+	}
+
+	return found, nil
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
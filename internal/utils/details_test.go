@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetLevelDetailsLoadsCredentials(t *testing.T) {
+	home := setupTestHome(t)
+	t.Setenv(passphraseEnvVar, "secret")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/submissions/user/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer stored" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode([]SubmissionData{{LevelName: "average", Gas: "100", Size: "50"}})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	writeTestConfig(t, filepath.Join(home, configFile), map[string]string{
+		"EVMR_LEVELS_DIR":       home,
+		"EVMR_SERVER":           server.URL + "/",
+		"EVMR_CREDENTIAL_STORE": CredentialStoreFile,
+	})
+	if err := (fileStore{}).Set(DefaultProfile, "stored"); err != nil {
+		t.Fatal(err)
+	}
+
+	// like 'evmr level show', the config is loaded without credentials
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	details := GetLevelDetails(config, Level{ID: "1", Contract: "Average", Pack: OfficialPack})
+	if details.Best == nil || details.Best.Gas != "100" {
+		t.Errorf("the user's scores were not fetched with the token of the credential store: %+v", details.Best)
+	}
+}
@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+)
+
+const (
+	appDirName    = "evm-runners"
+	legacyDirName = ".evm-runners"
+	homeEnvVar    = "EVMR_HOME"
+)
+
+// returns the directory of the config files, e.g. ~/.evm-runners or $XDG_CONFIG_HOME/evm-runners
+func ConfigDir() (string, error) {
+	return resolveDir("XDG_CONFIG_HOME", "")
+}
+
+// returns the directory of persistent data like credentials, e.g. ~/.evm-runners or $XDG_DATA_HOME/evm-runners
+func DataDir() (string, error) {
+	return resolveDir("XDG_DATA_HOME", "")
+}
+
+// returns the directory of the compilation cache, e.g. ~/.evm-runners/cache or $XDG_CACHE_HOME/evm-runners
+func CacheDir() (string, error) {
+	return resolveDir("XDG_CACHE_HOME", cacheDirName)
+}
+
+// resolves a directory in this order:
+//  1. EVMR_HOME, which holds config, data and cache (in a "cache" subdirectory)
+//  2. the XDG variable (XDG_CONFIG_HOME, XDG_DATA_HOME or XDG_CACHE_HOME) with an "evm-runners" subdirectory
+//  3. ~/.evm-runners, where the installer puts the binary and older versions put everything else
+func resolveDir(xdgEnvVar string, subdir string) (string, error) {
+	if evmrHome := os.Getenv(homeEnvVar); evmrHome != "" {
+		return filepath.Join(evmrHome, subdir), nil
+	}
+
+	// the XDG spec requires absolute paths, relative ones are ignored
+	if xdgDir := os.Getenv(xdgEnvVar); filepath.IsAbs(xdgDir) {
+		return filepath.Join(xdgDir, appDirName), nil
+	}
+
+	legacyDir, err := legacyDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(legacyDir, subdir), nil
+}
+
+// returns ~/.evm-runners
+func legacyDir() (string, error) {
+	home, err := homeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, legacyDirName), nil
+}
+
+// returns the user's home directory. $HOME is preferred, since user.Current() fails
+// without cgo in containers that have no passwd entry for the user.
+func homeDir() (string, error) {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home, nil
+	}
+
+	usr, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("error getting user's home directory: %v. Set %s to choose a directory for evm-runners", err, homeEnvVar)
+	}
+
+	return usr.HomeDir, nil
+}
+
+// moves the config files and credentials of an existing install in ~/.evm-runners to the directories
+// selected with EVMR_HOME or the XDG variables. The installed binary stays in ~/.evm-runners/bin.
+func MigrateLegacyDirs() error {
+	legacy, err := legacyDir()
+	if err != nil {
+		// without a home directory there is nothing to migrate
+		return nil
+	}
+
+	configDir, err := ConfigDir()
+	if err != nil {
+		return err
+	}
+
+	dataDir, err := DataDir()
+	if err != nil {
+		return err
+	}
+
+	// the config and the data directory can be selected independently, e.g. if only XDG_DATA_HOME is set
+	configMoved := false
+	if configDir != legacy {
+		moved, err := moveLegacyPaths([][2]string{
+			{filepath.Join(legacy, configFile), filepath.Join(configDir, configFile)},
+			{filepath.Join(legacy, profilesDir), filepath.Join(configDir, profilesDir)},
+		})
+		if err != nil {
+			return err
+		}
+		if moved {
+			fmt.Printf("Moved evm-runners config from '%s' to '%s'.\n\n", legacy, configDir)
+		}
+		configMoved = moved
+	}
+
+	// the credentials belong to the config next to them. If the new location already has a config of its own,
+	// the old credentials stay with the old config.
+	credentialsBelong := configDir == legacy || configMoved || !fileExists(filepath.Join(configDir, configFile))
+
+	if dataDir != legacy && credentialsBelong {
+		moved, err := moveLegacyPaths([][2]string{
+			{filepath.Join(legacy, credentialsFile), filepath.Join(dataDir, credentialsFile)},
+		})
+		if err != nil {
+			return err
+		}
+		if moved {
+			fmt.Printf("Moved evm-runners credentials from '%s' to '%s'.\n\n", legacy, dataDir)
+		}
+	}
+
+	return nil
+}
+
+// moves paths from ~/.evm-runners to their new location. Nothing is moved if the first path doesn't exist
+// or was already moved, so that an existing install in the new location isn't mixed with the old one.
+func moveLegacyPaths(moves [][2]string) (bool, error) {
+	if !fileExists(moves[0][0]) || fileExists(moves[0][1]) {
+		return false, nil
+	}
+
+	for _, move := range moves {
+		src, dst := move[0], move[1]
+		if !fileExists(src) {
+			continue
+		}
+
+		if err := movePath(src, dst); err != nil {
+			return false, fmt.Errorf("error moving '%s' to '%s': %v", src, dst, err)
+		}
+	}
+
+	return true, nil
+}
+
+// moves a file or directory, falling back to copy and delete across file systems
+func movePath(src string, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := movePath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+
+		return os.Remove(src)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(src)
+}
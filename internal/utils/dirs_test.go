@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// creates an install of an older version in ~/.evm-runners
+func writeLegacyInstall(t *testing.T, home string) string {
+	t.Helper()
+
+	legacy := filepath.Join(home, legacyDirName)
+	writeTestConfig(t, filepath.Join(legacy, configFile), map[string]string{"EVMR_ID": "5"})
+	writeTestConfig(t, filepath.Join(legacy, profilesDir, "staging"+configFile), nil)
+	if err := os.WriteFile(filepath.Join(legacy, credentialsFile), []byte("encrypted"), credentialFileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	return legacy
+}
+
+func TestMigrateLegacyDirsDataOnly(t *testing.T) {
+	home := setupTestHome(t)
+	t.Setenv(homeEnvVar, "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	legacy := writeLegacyInstall(t, home)
+
+	if err := MigrateLegacyDirs(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !fileExists(filepath.Join(home, "data", appDirName, credentialsFile)) {
+		t.Errorf("credentials were not moved to XDG_DATA_HOME")
+	}
+	if fileExists(filepath.Join(legacy, credentialsFile)) {
+		t.Errorf("credentials were left in '%s'", legacy)
+	}
+	if !fileExists(filepath.Join(legacy, configFile)) {
+		t.Errorf("config file was moved, although the config directory didn't change")
+	}
+}
+
+func TestMigrateLegacyDirsEvmrHome(t *testing.T) {
+	home := setupTestHome(t)
+	evmrHome := filepath.Join(home, "evmr")
+	t.Setenv(homeEnvVar, evmrHome)
+	legacy := writeLegacyInstall(t, home)
+
+	if err := MigrateLegacyDirs(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{configFile, filepath.Join(profilesDir, "staging"+configFile), credentialsFile} {
+		if !fileExists(filepath.Join(evmrHome, name)) {
+			t.Errorf("%s was not moved to EVMR_HOME", name)
+		}
+		if fileExists(filepath.Join(legacy, name)) {
+			t.Errorf("%s was left in '%s'", name, legacy)
+		}
+	}
+}
+
+func TestMigrateLegacyDirsKeepsNewInstall(t *testing.T) {
+	home := setupTestHome(t)
+	evmrHome := filepath.Join(home, "evmr")
+	t.Setenv(homeEnvVar, evmrHome)
+	legacy := writeLegacyInstall(t, home)
+	writeTestConfig(t, filepath.Join(evmrHome, configFile), map[string]string{"EVMR_ID": "7"})
+
+	if err := MigrateLegacyDirs(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the old config and its credentials stay together in the old install
+	if !fileExists(filepath.Join(legacy, configFile)) || fileExists(filepath.Join(evmrHome, profilesDir)) {
+		t.Errorf("the old config was mixed into the new install")
+	}
+	if fileExists(filepath.Join(evmrHome, credentialsFile)) || !fileExists(filepath.Join(legacy, credentialsFile)) {
+		t.Errorf("the old credentials were moved into the new install")
+	}
+}
+
+func TestMigrateLegacyDirsCredentialsWithoutConfig(t *testing.T) {
+	home := setupTestHome(t)
+	evmrHome := filepath.Join(home, "evmr")
+	t.Setenv(homeEnvVar, evmrHome)
+	legacy := writeLegacyInstall(t, home)
+	if err := os.Remove(filepath.Join(legacy, configFile)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MigrateLegacyDirs(); err != nil {
+		t.Fatal(err)
+	}
+
+	// no config exists at either location, so the credentials don't belong to another install
+	if !fileExists(filepath.Join(evmrHome, credentialsFile)) {
+		t.Errorf("credentials were not moved")
+	}
+}
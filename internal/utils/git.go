@@ -0,0 +1,422 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage"
+)
+
+const (
+	LevelsRepoURL = "https://github.com/ethernautdao/evm-runners-levels.git"
+
+	// how local modifications are handled when updating the levels
+	ModifiedStash = "stash"
+	ModifiedSkip  = "skip"
+	ModifiedAbort = "abort"
+
+	stashDir = "stash"
+)
+
+// clones a git repository into dir. ref can be a branch, a tag or a commit, the default branch is used if it is empty.
+// If cloning fails, the partial clone is removed, unless dir existed before.
+func CloneRepository(url string, ref string, dir string) error {
+	existed := fileExists(dir)
+
+	err := cloneRepository(url, ref, dir)
+	if err != nil && !existed {
+		os.RemoveAll(dir)
+	}
+
+	return err
+}
+
+func cloneRepository(url string, ref string, dir string) error {
+	if ref == "" {
+		if _, err := git.PlainClone(dir, false, &git.CloneOptions{URL: url, Progress: os.Stdout}); err != nil {
+			return fmt.Errorf("error cloning the repository: %v", err)
+		}
+		return nil
+	}
+
+	// try the ref as a branch and as a tag first, which only fetches that ref
+	for _, name := range []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref)} {
+		_, err := git.PlainClone(dir, false, &git.CloneOptions{
+			URL:           url,
+			ReferenceName: name,
+			SingleBranch:  name.IsBranch(),
+			Progress:      os.Stdout,
+		})
+		if err == nil {
+			return nil
+		}
+		if !isNoMatchingRef(err) {
+			return fmt.Errorf("error cloning the repository: %v", err)
+		}
+
+		// the next attempt needs an empty directory
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+
+	// otherwise clone everything and check out the ref as a commit
+	repo, err := git.PlainClone(dir, false, &git.CloneOptions{URL: url, Progress: os.Stdout})
+	if err != nil {
+		return fmt.Errorf("error cloning the repository: %v", err)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return fmt.Errorf("'%s' is not a branch, tag or commit of '%s'\n", ref, url)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	if err := wt.Checkout(&git.CheckoutOptions{Hash: *hash}); err != nil {
+		return fmt.Errorf("error checking out '%s': %v", ref, err)
+	}
+
+	return nil
+}
+
+// reports whether cloning failed because the requested ref doesn't exist
+func isNoMatchingRef(err error) bool {
+	var noMatch git.NoMatchingRefSpecError
+	return errors.As(err, &noMatch) || errors.Is(err, plumbing.ErrReferenceNotFound)
+}
+
+// reports whether dir is a git repository, i.e. whether it can be updated
+func IsGitRepository(dir string) bool {
+	_, err := git.PlainOpen(dir)
+	return err == nil
+}
+
+// a fast-forward of the levels repository to its remote branch
+type LevelsUpdate struct {
+	repo     *git.Repository
+	worktree *git.Worktree
+	head     *object.Commit
+	remote   *object.Commit
+	changes  object.Changes
+
+	// number of new commits
+	Commits int
+	// files changed by the update
+	Changed map[string]bool
+	// tracked files with local modifications and untracked files that would be overwritten by the update
+	Modified []string
+}
+
+// fetches the levels repository and prepares a fast-forward to its remote branch.
+// Returns nil if the levels are up to date. The fetch progress is written to progress, if not nil.
+func FetchLevelsUpdate(dir string, progress io.Writer) (*LevelsUpdate, error) {
+	repo, err := git.PlainOpen(dir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, fmt.Errorf("'%s' is not a git repository, so it can't be updated. Run 'evmr init' again to install a newer version of the levels.\n", dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening the levels repository: %v", err)
+	}
+
+	err = fetchOrigin(repo, progress)
+	if errors.Is(err, storage.ErrReferenceHasChanged) {
+		// go-git fails to update refs that are only stored in packed-refs, as in clones by the git CLI.
+		// The failed attempt unpacks the ref, so a second attempt succeeds.
+		err = fetchOrigin(repo, progress)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	headRef, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("error reading HEAD: %v", err)
+	}
+
+	if !headRef.Name().IsBranch() {
+		return nil, fmt.Errorf("the levels repository is not on a branch, e.g. because it was initialized with '--ref <tag>', so it can't be updated.\nRun 'git checkout main' in '%s' first.\n", dir)
+	}
+
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", headRef.Name().Short()), true)
+	if err != nil {
+		return nil, fmt.Errorf("no remote branch found for '%s': %v", headRef.Name().Short(), err)
+	}
+
+	head, err := repo.CommitObject(headRef.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := repo.CommitObject(remoteRef.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	if head.Hash == remote.Hash {
+		return nil, nil
+	}
+
+	if ahead, err := remote.IsAncestor(head); err != nil {
+		return nil, err
+	} else if ahead {
+		// local commits that are not pushed, nothing to update
+		return nil, nil
+	}
+
+	if canFastForward, err := head.IsAncestor(remote); err != nil {
+		return nil, err
+	} else if !canFastForward {
+		return nil, fmt.Errorf("the levels repository has local commits that are not on the remote branch, so it can't be updated automatically. Resolve this with git in '%s'.\n", dir)
+	}
+
+	update := &LevelsUpdate{
+		repo:    repo,
+		head:    head,
+		remote:  remote,
+		Changed: make(map[string]bool),
+	}
+
+	// count the new commits
+	iter, err := repo.Log(&git.LogOptions{From: remote.Hash})
+	if err != nil {
+		return nil, err
+	}
+	_ = iter.ForEach(func(c *object.Commit) error {
+		if c.Hash == head.Hash {
+			return storer.ErrStop
+		}
+		update.Commits++
+		return nil
+	})
+
+	headTree, err := head.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	remoteTree, err := remote.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	update.changes, err = object.DiffTree(headTree, remoteTree)
+	if err != nil {
+		return nil, fmt.Errorf("error comparing commits: %v", err)
+	}
+
+	for _, change := range update.changes {
+		if change.From.Name != "" {
+			update.Changed[change.From.Name] = true
+		}
+		if change.To.Name != "" {
+			update.Changed[change.To.Name] = true
+		}
+	}
+
+	update.worktree, err = repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := update.worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("error checking for local modifications: %v", err)
+	}
+
+	for path, s := range status {
+		if s.Worktree == git.Untracked {
+			// untracked files are only a problem if the update adds a file with the same name
+			if update.Changed[path] {
+				update.Modified = append(update.Modified, path)
+			}
+			continue
+		}
+
+		if s.Worktree != git.Unmodified || s.Staging != git.Unmodified {
+			update.Modified = append(update.Modified, path)
+		}
+	}
+	sort.Strings(update.Modified)
+
+	return update, nil
+}
+
+func fetchOrigin(repo *git.Repository, progress io.Writer) error {
+	err := repo.Fetch(&git.FetchOptions{
+		RemoteName: "origin",
+		Progress:   progress,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		if errors.Is(err, storage.ErrReferenceHasChanged) {
+			return err
+		}
+		return fmt.Errorf("error fetching the levels repository: %v", err)
+	}
+
+	return nil
+}
+
+// applies the update. Local modifications to files that are not changed by the update are always kept.
+// Modified files that are changed by the update are handled depending on mode:
+//   - stash: the modified files are copied to a stash directory and replaced with the updated files
+//   - skip: the modified files are kept, and the update isn't applied to them
+//
+// Returns the stash directory and the files that were stashed or skipped because the update changed them.
+func (u *LevelsUpdate) Apply(mode string) (string, []string, error) {
+	if mode != ModifiedStash && mode != ModifiedSkip {
+		return "", nil, fmt.Errorf("invalid mode '%s'", mode)
+	}
+
+	root := u.worktree.Filesystem.Root()
+
+	var conflicts []string
+	keep := make(map[string]bool)
+	for _, path := range u.Modified {
+		if u.Changed[path] {
+			conflicts = append(conflicts, path)
+			if mode == ModifiedSkip {
+				keep[path] = true
+			}
+		}
+	}
+
+	stash := ""
+	if mode == ModifiedStash && len(u.Modified) > 0 {
+		dataDir, err := DataDir()
+		if err != nil {
+			return "", nil, err
+		}
+
+		stash = filepath.Join(dataDir, stashDir, time.Now().Format("20060102-150405"))
+		for _, path := range u.Modified {
+			src := filepath.Join(root, path)
+			if !fileExists(src) {
+				continue
+			}
+			if err := copyPath(src, filepath.Join(stash, path)); err != nil {
+				return "", nil, fmt.Errorf("error stashing '%s': %v", path, err)
+			}
+		}
+	}
+
+	// the files are updated one by one, since resetting the worktree with go-git also deletes untracked files
+	for _, change := range u.changes {
+		from, to := change.From.Name, change.To.Name
+
+		if from != "" && from != to && !keep[from] {
+			if err := removeFile(root, from); err != nil {
+				return stash, conflicts, err
+			}
+		}
+
+		if to != "" && !keep[to] {
+			if err := writeTreeFile(root, u.remote, to); err != nil {
+				return stash, conflicts, err
+			}
+		}
+	}
+
+	// move the branch and the index to the remote commit, leaving the worktree as it is
+	if err := u.worktree.Reset(&git.ResetOptions{Commit: u.remote.Hash, Mode: git.MixedReset}); err != nil {
+		return stash, conflicts, fmt.Errorf("error updating the branch: %v", err)
+	}
+
+	return stash, conflicts, nil
+}
+
+// writes a file of a commit to the worktree
+func writeTreeFile(root string, commit *object.Commit, path string) error {
+	file, err := commit.File(path)
+	if err != nil {
+		return fmt.Errorf("error reading '%s': %v", path, err)
+	}
+
+	dst := filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	// replace the file instead of writing into it, in case it is a symlink or its mode changed
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if file.Mode == filemode.Symlink {
+		target, err := file.Contents()
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	}
+
+	perm := os.FileMode(0644)
+	if file.Mode == filemode.Executable {
+		perm = 0755
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("error writing '%s': %v", path, err)
+	}
+
+	if _, err := io.Copy(out, reader); err != nil {
+		out.Close()
+		return fmt.Errorf("error writing '%s': %v", path, err)
+	}
+
+	return out.Close()
+}
+
+// removes a file from the worktree, and its parent directories if they are empty
+func removeFile(root string, path string) error {
+	if err := os.Remove(filepath.Join(root, path)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing '%s': %v", path, err)
+	}
+
+	for dir := filepath.Dir(path); dir != "."; dir = filepath.Dir(dir) {
+		if os.Remove(filepath.Join(root, dir)) != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+// copies a file, creating the parent directories of the destination
+func copyPath(src string, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(dst, data, info.Mode().Perm())
+}
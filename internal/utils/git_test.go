@@ -0,0 +1,315 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// a levels repository that is pushed to a local bare repository, from which the levels are cloned
+type testUpstream struct {
+	t    *testing.T
+	repo *git.Repository
+	dir  string
+	bare string
+}
+
+func newTestUpstream(t *testing.T, files map[string]string) *testUpstream {
+	t.Helper()
+
+	u := &testUpstream{t: t, dir: t.TempDir(), bare: t.TempDir()}
+
+	if _, err := git.PlainInit(u.bare, true); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	if u.repo, err = git.PlainInit(u.dir, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{u.bare}}); err != nil {
+		t.Fatal(err)
+	}
+
+	u.commit(files)
+	return u
+}
+
+// commits and pushes files, an empty content deletes the file
+func (u *testUpstream) commit(files map[string]string) {
+	u.t.Helper()
+
+	wt, err := u.repo.Worktree()
+	if err != nil {
+		u.t.Fatal(err)
+	}
+
+	for path, content := range files {
+		if content == "" {
+			if _, err := wt.Remove(path); err != nil {
+				u.t.Fatal(err)
+			}
+			continue
+		}
+		writeTestFile(u.t, filepath.Join(u.dir, filepath.FromSlash(path)), content)
+		if _, err := wt.Add(path); err != nil {
+			u.t.Fatal(err)
+		}
+	}
+
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	if _, err := wt.Commit("update", &git.CommitOptions{Author: signature}); err != nil {
+		u.t.Fatal(err)
+	}
+	if err := u.repo.Push(&git.PushOptions{RemoteName: "origin"}); err != nil {
+		u.t.Fatal(err)
+	}
+}
+
+// clones the bare repository like 'evmr init'
+func (u *testUpstream) clone() string {
+	u.t.Helper()
+
+	dir := filepath.Join(u.t.TempDir(), "levels")
+	if _, err := git.PlainClone(dir, false, &git.CloneOptions{URL: u.bare}); err != nil {
+		u.t.Fatal(err)
+	}
+	return dir
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func headHash(t *testing.T, dir string) string {
+	t.Helper()
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return head.Hash().String()
+}
+
+// returns the paths with local modifications
+func worktreeChanges(t *testing.T, dir string) []string {
+	t.Helper()
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := wt.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for path, s := range status {
+		if s.Worktree != git.Unmodified || s.Staging != git.Unmodified {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func TestFetchLevelsUpdateUpToDate(t *testing.T) {
+	upstream := newTestUpstream(t, map[string]string{"levels.toml": "v1"})
+	dir := upstream.clone()
+
+	update, err := FetchLevelsUpdate(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update != nil {
+		t.Errorf("got an update with %d commits for an up to date repository", update.Commits)
+	}
+}
+
+func TestLevelsUpdateStash(t *testing.T) {
+	home := setupTestHome(t)
+	upstream := newTestUpstream(t, map[string]string{"levels.toml": "v1", "src/Average.sol": "template v1", "README.md": "readme"})
+	dir := upstream.clone()
+
+	writeTestFile(t, filepath.Join(dir, "src", "Average.sol"), "my solution")
+	writeTestFile(t, filepath.Join(dir, "README.md"), "my notes")
+	upstream.commit(map[string]string{"levels.toml": "v2", "src/Average.sol": "template v2"})
+	upstream.commit(map[string]string{"levels.toml": "v3"})
+
+	update, err := FetchLevelsUpdate(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update.Commits != 2 {
+		t.Errorf("Commits = %d, want 2", update.Commits)
+	}
+	if want := []string{"README.md", "src/Average.sol"}; !reflect.DeepEqual(update.Modified, want) {
+		t.Errorf("Modified = %v, want %v", update.Modified, want)
+	}
+
+	stash, conflicts, err := update.Apply(ModifiedStash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"src/Average.sol"}; !reflect.DeepEqual(conflicts, want) {
+		t.Errorf("conflicts = %v, want %v", conflicts, want)
+	}
+
+	// the modified files are stashed, the update replaces the conflicting one
+	if !strings.HasPrefix(stash, filepath.Join(home, stashDir)) {
+		t.Errorf("stash '%s' is not in the data directory", stash)
+	}
+	if got := readTestFile(t, filepath.Join(stash, "src", "Average.sol")); got != "my solution" {
+		t.Errorf("stashed solution = %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(stash, "README.md")); got != "my notes" {
+		t.Errorf("stashed readme = %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "src", "Average.sol")); got != "template v2" {
+		t.Errorf("src/Average.sol = %q, want the updated template", got)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "levels.toml")); got != "v3" {
+		t.Errorf("levels.toml = %q, want v3", got)
+	}
+
+	// modifications of files that the update doesn't change are kept
+	if got := readTestFile(t, filepath.Join(dir, "README.md")); got != "my notes" {
+		t.Errorf("README.md = %q, want the local modification", got)
+	}
+	if got := worktreeChanges(t, dir); !reflect.DeepEqual(got, []string{"README.md"}) {
+		t.Errorf("local changes after the update = %v, want [README.md]", got)
+	}
+	if headHash(t, dir) != headHash(t, upstream.dir) {
+		t.Errorf("the branch was not moved to the remote commit")
+	}
+}
+
+func TestLevelsUpdateSkip(t *testing.T) {
+	setupTestHome(t)
+	upstream := newTestUpstream(t, map[string]string{"levels.toml": "v1", "src/Average.sol": "template v1"})
+	dir := upstream.clone()
+
+	writeTestFile(t, filepath.Join(dir, "src", "Average.sol"), "my solution")
+	// an untracked file is only a conflict if the update adds a file with the same name
+	writeTestFile(t, filepath.Join(dir, "src", "Sqrt.sol"), "my sqrt")
+	writeTestFile(t, filepath.Join(dir, "src", "Notes.md"), "notes")
+	upstream.commit(map[string]string{"levels.toml": "v2", "src/Average.sol": "template v2", "src/Sqrt.sol": "sqrt template"})
+
+	update, err := FetchLevelsUpdate(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"src/Average.sol", "src/Sqrt.sol"}; !reflect.DeepEqual(update.Modified, want) {
+		t.Errorf("Modified = %v, want %v", update.Modified, want)
+	}
+
+	stash, conflicts, err := update.Apply(ModifiedSkip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stash != "" {
+		t.Errorf("files were stashed in skip mode")
+	}
+	if want := []string{"src/Average.sol", "src/Sqrt.sol"}; !reflect.DeepEqual(conflicts, want) {
+		t.Errorf("conflicts = %v, want %v", conflicts, want)
+	}
+
+	for path, want := range map[string]string{
+		"src/Average.sol": "my solution",
+		"src/Sqrt.sol":    "my sqrt",
+		"src/Notes.md":    "notes",
+		"levels.toml":     "v2",
+	} {
+		if got := readTestFile(t, filepath.Join(dir, filepath.FromSlash(path))); got != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+	if headHash(t, dir) != headHash(t, upstream.dir) {
+		t.Errorf("the branch was not moved to the remote commit")
+	}
+}
+
+func TestLevelsUpdateDeletedFile(t *testing.T) {
+	setupTestHome(t)
+	upstream := newTestUpstream(t, map[string]string{"levels.toml": "v1", "test/old/Old.t.sol": "old test", "test/Average.t.sol": "test"})
+	dir := upstream.clone()
+
+	upstream.commit(map[string]string{"test/old/Old.t.sol": ""})
+
+	update, err := FetchLevelsUpdate(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !update.Changed["test/old/Old.t.sol"] {
+		t.Errorf("Changed = %v, want the deleted file", update.Changed)
+	}
+
+	if _, _, err := update.Apply(ModifiedStash); err != nil {
+		t.Fatal(err)
+	}
+
+	// the file and its empty directory are removed, other files are kept
+	if fileExists(filepath.Join(dir, "test", "old")) {
+		t.Errorf("the deleted file or its directory is still there")
+	}
+	if !fileExists(filepath.Join(dir, "test", "Average.t.sol")) {
+		t.Errorf("a file that wasn't deleted was removed")
+	}
+	if got := worktreeChanges(t, dir); len(got) != 0 {
+		t.Errorf("local changes after the update = %v, want none", got)
+	}
+}
+
+func TestLevelsUpdateAbort(t *testing.T) {
+	setupTestHome(t)
+	upstream := newTestUpstream(t, map[string]string{"levels.toml": "v1", "src/Average.sol": "template v1"})
+	dir := upstream.clone()
+	head := headHash(t, dir)
+
+	writeTestFile(t, filepath.Join(dir, "src", "Average.sol"), "my solution")
+	upstream.commit(map[string]string{"levels.toml": "v2", "src/Average.sol": "template v2"})
+
+	update, err := FetchLevelsUpdate(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 'evmr update' doesn't apply the update if the user aborts, and Apply rejects the mode
+	if _, _, err := update.Apply(ModifiedAbort); err == nil {
+		t.Errorf("Apply(%q) succeeded", ModifiedAbort)
+	}
+
+	if headHash(t, dir) != head {
+		t.Errorf("the branch was moved")
+	}
+	if got := readTestFile(t, filepath.Join(dir, "src", "Average.sol")); got != "my solution" {
+		t.Errorf("src/Average.sol = %q, want the local modification", got)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "levels.toml")); got != "v1" {
+		t.Errorf("levels.toml = %q, want v1", got)
+	}
+	if got := worktreeChanges(t, dir); !reflect.DeepEqual(got, []string{"src/Average.sol"}) {
+		t.Errorf("local changes = %v, want [src/Average.sol]", got)
+	}
+}
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -37,6 +40,51 @@ type SubmissionData struct {
 
 // Runs the forge test command with random values for block parameters
 func RunTest(levelsDir string, testContract string, verbose bool) ([]byte, error) {
+	return testCommand(context.Background(), levelsDir, testContract, verbose).CombinedOutput()
+}
+
+// runs the tests like RunTest and sends each line of the output to lines while the tests are running.
+// lines is closed when the tests finished. The tests are stopped if ctx is canceled.
+func StreamTest(ctx context.Context, levelsDir string, testContract string, verbose bool, lines chan<- string) ([]byte, error) {
+	defer close(lines)
+
+	execCmd := testCommand(ctx, levelsDir, testContract, verbose)
+
+	reader, writer := io.Pipe()
+	execCmd.Stdout = writer
+	execCmd.Stderr = writer
+
+	if err := execCmd.Start(); err != nil {
+		return nil, err
+	}
+
+	done := make(chan error)
+	go func() {
+		err := execCmd.Wait()
+		writer.Close()
+		done <- err
+	}()
+
+	var output bytes.Buffer
+	scanner := bufio.NewScanner(reader)
+	// stack traces can have long lines
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		output.WriteString(scanner.Text() + "\n")
+		// nobody reads the lines after the tests were canceled
+		select {
+		case lines <- scanner.Text():
+		case <-ctx.Done():
+		}
+	}
+	// keep the tests from blocking on a line that was too long
+	io.Copy(&output, reader)
+
+	return output.Bytes(), <-done
+}
+
+// returns the forge test command with random values for block parameters
+func testCommand(ctx context.Context, levelsDir string, testContract string, verbose bool) *exec.Cmd {
 	// seed random number generator
 	rand.Seed(time.Now().UnixNano())
 
@@ -61,7 +109,7 @@ func RunTest(levelsDir string, testContract string, verbose bool) ([]byte, error
 	randPrevRandao := "0x" + hex.EncodeToString(bytes)
 
 	// initialize the command with common arguments
-	execCmd := exec.Command("forge", "test",
+	execCmd := exec.CommandContext(ctx, "forge", "test",
 		"--block-coinbase", randAddress,
 		"--block-timestamp", strconv.Itoa(randTimestamp),
 		"--block-number", strconv.Itoa(rand.Intn(17243073)),
@@ -79,21 +127,14 @@ func RunTest(levelsDir string, testContract string, verbose bool) ([]byte, error
 	}
 
 	execCmd.Dir = levelsDir
-	output, err := execCmd.CombinedOutput()
 
-	// Check for errors
-	if err != nil {
-		return output, err
-	}
-
-	return output, nil
+	return execCmd
 }
 
 // fetchSubmissionData function to fetch existing submission data
-func FetchSubmissionData(config Config) ([]SubmissionData, error) {
+func FetchSubmissionData(config *Config) ([]SubmissionData, error) {
 	url := config.EVMR_SERVER + "submissions/user/"
 	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+config.EVMR_TOKEN)
 
 	// Create submission data struct
 	var submissions []SubmissionData
@@ -103,11 +144,10 @@ func FetchSubmissionData(config Config) ([]SubmissionData, error) {
 		Timeout: 1 * time.Second,
 	}
 
-	resp, err := client.Do(req)
+	resp, body, err := DoAuthorized(config, req, client)
 	if err != nil {
-		return nil, fmt.Errorf("error sending the request: %v", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
 	// Check for errors in the response
 	if resp.StatusCode != http.StatusOK {
@@ -116,20 +156,14 @@ func FetchSubmissionData(config Config) ([]SubmissionData, error) {
 			return submissions, nil
 		}
 
-		// if its 400, recommend running "auth" again
+		// a 400 without an invalid token error code can still be caused by a token of an older server version
 		if resp.StatusCode == http.StatusBadRequest {
-			return nil, fmt.Errorf("bad request, try running running 'evmr auth' again")
+			return nil, fmt.Errorf("bad request, try running 'evmr auth' again")
 		}
 
 		return nil, fmt.Errorf("http request failed with status: %s", resp.Status)
 	}
 
-	// Read the response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading the response: %v", err)
-	}
-
 	// Parse the response
 	err = json.Unmarshal(body, &submissions)
 	if err != nil {
@@ -139,7 +173,7 @@ func FetchSubmissionData(config Config) ([]SubmissionData, error) {
 	return submissions, nil
 }
 
-// Returns the amount of solves per level
+// Returns the amount of solves per level, keyed like levels
 func GetSolves(levels map[string]Level) map[string]string {
 	solves := make(map[string]string)
 
@@ -149,38 +183,106 @@ func GetSolves(levels map[string]Level) map[string]string {
 
 	}
 
+	servers := packServers(config)
+
 	// Create a custom HTTP client with a 1-second timeout
 	client := &http.Client{
 		Timeout: 1 * time.Second,
 	}
 
 	for key := range levels {
-		url := fmt.Sprintf("%slevels/%s/total", config.EVMR_SERVER, levels[key].ID)
-		resp, err := client.Get(url)
+		solves[key] = fetchSolves(client, servers[levels[key].Pack], levels[key].ID)
+	}
 
-		// if the get request errors for some reason, we just set solves to an empty string
-		if err != nil {
-			solves[levels[key].Contract] = ""
-			continue
-		}
-		defer resp.Body.Close()
+	return solves
+}
+
+// returns the amount of solves of a level, or an empty string if it can't be fetched
+func fetchSolves(client *http.Client, server string, levelId string) string {
+	url := fmt.Sprintf("%slevels/%s/total", server, levelId)
+	resp, err := client.Get(url)
+
+	// if the get request errors for some reason, we just return an empty string
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	// Check for errors in the response
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ""
+	}
+
+	return string(body)
+}
+
+// Returns "x" for each level the user solved, keyed like levels. Submissions are fetched from the server of each pack.
+// Only errors of EVMR_SERVER are returned, levels of unreachable pack servers are just shown as unsolved.
+func GetSolved(config *Config, levels map[string]Level) (map[string]string, error) {
+	solved := make(map[string]string)
+	for key := range levels {
+		solved[key] = ""
+	}
+
+	if err := LoadCredentials(config); err != nil {
+		return solved, err
+	}
 
-		// Check for errors in the response
-		if resp.StatusCode != http.StatusOK {
-			solves[levels[key].Contract] = ""
-			continue
+	if config.EVMR_TOKEN == "" {
+		return solved, nil
+	}
+
+	servers := packServers(*config)
+
+	// packs usually share a server, so each server is only asked once
+	byServer := make(map[string][]SubmissionData)
+	for key, level := range levels {
+		server := servers[level.Pack]
+
+		submissions, ok := byServer[server]
+		if !ok {
+			byServer[server] = nil
+
+			// the user is only authenticated against EVMR_SERVER
+			if server != config.EVMR_SERVER {
+				continue
+			}
+
+			var err error
+			if submissions, err = FetchSubmissionData(config); err != nil {
+				return solved, err
+			}
+			byServer[server] = submissions
 		}
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			solves[levels[key].Contract] = ""
-			continue
+		for _, item := range submissions {
+			if strings.EqualFold(item.LevelName, level.Contract) {
+				solved[key] = "x"
+			}
 		}
+	}
 
-		solves[levels[key].Contract] = string(body)
+	return solved, nil
+}
+
+// returns the server of each pack
+func packServers(config Config) map[string]string {
+	servers := make(map[string]string)
+
+	packs, _ := LoadPacks(config)
+	for _, pack := range packs {
+		servers[pack.Name] = config.EVMR_SERVER
+		if pack.Server != "" {
+			servers[pack.Name] = pack.Server
+		}
 	}
 
-	return solves
+	return servers
 }
 
 // parseOutput function to parse gas and size values of output from forge test
@@ -218,11 +320,11 @@ func ParseOutput(output string) (int, int, error) {
 	return gasValue, sizeValue, nil
 }
 
-// compiles the solution file and returns the bytecode + solution type (e.g. sol, yul, vyper, huff)
-func GetBytecodeToValidate(bytecode string, level string, filename string, levelsDir string, lang string) (string, string, error) {
+// compiles the solution file and returns the bytecode + solution type (e.g. sol, yul, vyper, huff) + effective compiler args
+func GetBytecodeToValidate(bytecode string, level string, filename string, levelsDir string, solutionsDir string, lang string, compilerArgs string) (string, string, []string, error) {
 	levels, err := LoadLevels()
 	if err != nil {
-		return "", "", nil
+		return "", "", nil, nil
 	}
 
 	// check if bytecode was provided, if yes compile the source code
@@ -230,117 +332,116 @@ func GetBytecodeToValidate(bytecode string, level string, filename string, level
 		// check if bytecode is valid
 		bytecode, err := sanitizeBytecode(bytecode)
 		if err != nil {
-			return "", "", err
+			return "", "", nil, err
 		}
 
-		return bytecode, "bytecode", nil
+		return bytecode, "bytecode", nil, nil
 	} else {
-		solutionType, err := getSolutionType(filename, lang)
+		solutionType, err := getSolutionType(solutionsDir, filename, lang)
 		if err != nil {
-			return "", "", err
+			return "", "", nil, err
+		}
+		sourcePath := solutionPath(solutionsDir, filename, solutionType)
+
+		// get compiler args from build profile and command line
+		args, err := GetCompilerArgs(solutionsDir, levels[level].Contract, solutionType, compilerArgs)
+		if err != nil {
+			return "", "", nil, err
+		}
+
+		// return cached bytecode if the solution was compiled before with the same compiler and flags
+		cacheKey, err := compilationCacheKey(levelsDir, sourcePath, solutionType, levels[level].Contract, args)
+		if err != nil {
+			return "", "", nil, err
+		}
+		if cached, ok := readCachedBytecode(cacheKey); ok {
+			return cached, solutionType, args, nil
 		}
 
 		// .sol solution
 		if solutionType == "sol" {
-			// Compile all contracts
-			execCmd := exec.Command("forge", "build")
-			execCmd.Dir = levelsDir
-			output, err := execCmd.CombinedOutput()
-			if err != nil {
-				return "", "", fmt.Errorf("%s: %s", err, output)
-			}
-
-			// Read the JSON file
-			file, err := os.ReadFile(filepath.Join(levelsDir, "out", fmt.Sprintf("%s.sol", filename), fmt.Sprintf("%s.json", levels[level].Contract)))
+			// Compile the solution
+			outDir, err := buildSolidity(levelsDir, sourcePath, filename, args)
 			if err != nil {
-				return "", "", fmt.Errorf("error reading JSON file: %v", err)
+				return "", "", nil, err
 			}
 
-			// Parse the JSON data
-			var data map[string]interface{}
-			err = json.Unmarshal([]byte(file), &data)
+			// Extract the bytecode from the artifact
+			bytecode, err = readSolidityBytecode(outDir, filename, levels[level].Contract)
 			if err != nil {
-				return "", "", fmt.Errorf("error parsing JSON data: %v", err)
+				return "", "", nil, err
 			}
 
-			// Extract the "bytecode" field
-			bytecodeField := data["bytecode"].(map[string]interface{})
-
-			bytecode, err = sanitizeBytecode(bytecodeField["object"].(string))
+			bytecode, err = sanitizeBytecode(bytecode)
 			if err != nil {
-				return "", "", err
+				return "", "", nil, err
 			}
 		}
 
 		// .yul solution
 		if solutionType == "yul" {
 			// Compile the solution
-			yulPath := filepath.Join("src", fmt.Sprintf("%s.yul", filename))
-			// execute this command: solc --yul src/', string.concat(fileName, ".yul --bin | tail -1)
-			execCmd := exec.Command("solc", "--strict-assembly", yulPath, "--bin")
+			// execute this command: solc --strict-assembly <solution>.yul --bin
+			execCmd := exec.Command("solc", append([]string{"--strict-assembly", sourcePath, "--bin"}, args...)...)
 			execCmd.Dir = levelsDir
 			output, err := execCmd.CombinedOutput()
 			if err != nil {
-				return "", "", fmt.Errorf("%s: %s", err, output)
+				return "", "", nil, fmt.Errorf("%s: %s", err, output)
 			}
 
 			// Parse the output to extract the bytecode
 			bytecode, err = extractBytecode(string(output))
 			if err != nil {
-				return "", "", fmt.Errorf("error extracting bytecode: %s", err)
+				return "", "", nil, fmt.Errorf("error extracting bytecode: %s", err)
 			}
 
 			bytecode, err = sanitizeBytecode(bytecode)
 			if err != nil {
-				return "", "", err
+				return "", "", nil, err
 			}
 		}
 
 		// .vy solution
 		if solutionType == "vy" {
 			// Compile the solution
-			vyPath := filepath.Join("src", fmt.Sprintf("%s.vy", filename))
-			execCmd := exec.Command("vyper", vyPath)
+			execCmd := exec.Command("vyper", append(args, sourcePath)...)
 			execCmd.Dir = levelsDir
 			output, err := execCmd.CombinedOutput()
 			if err != nil {
-				return "", "", fmt.Errorf("%s: %s", err, output)
+				return "", "", nil, fmt.Errorf("%s: %s", err, output)
 			}
 
 			bytecode, err = sanitizeBytecode(string(output))
 			if err != nil {
-				return "", "", err
+				return "", "", nil, err
 			}
 		}
 
 		// .huff solution
 		if solutionType == "huff" {
 			// Compile the solution
-			huffPath := filepath.Join("src", fmt.Sprintf("%s.huff", filename))
-			execCmd := exec.Command("huffc", huffPath, "--bytecode")
+			execCmd := exec.Command("huffc", append([]string{sourcePath, "--bytecode"}, args...)...)
 			execCmd.Dir = levelsDir
 			output, err := execCmd.CombinedOutput()
 			if err != nil {
-				return "", "", fmt.Errorf("%s: %s", err, output)
+				return "", "", nil, fmt.Errorf("%s: %s", err, output)
 			}
 
 			bytecode, err = sanitizeBytecode(string(output))
 			if err != nil {
-				return "", "", err
+				return "", "", nil, err
 			}
 		}
 
-		return bytecode, solutionType, nil
+		// a failing cache write only means compiling again next time
+		_ = writeCachedBytecode(cacheKey, bytecode)
+
+		return bytecode, solutionType, args, nil
 	}
 }
 
 // returns the type of the solution file (e.g. sol, yul, vyper, huff)
-func getSolutionType(file string, langFlag string) (string, error) {
-	config, err := LoadConfig()
-	if err != nil {
-		return "", fmt.Errorf("error loading config: %v", err)
-	}
-
+func getSolutionType(solutionsDir string, file string, langFlag string) (string, error) {
 	// Define the supported languages and their file extensions
 	languages := map[string]string{
 		"sol":  ".sol",
@@ -369,24 +470,18 @@ func getSolutionType(file string, langFlag string) (string, error) {
 		}
 
 		// Check existence of specific solution file
-		filePath := filepath.Join(config.EVMR_LEVELS_DIR, solutionDir, file+languages[langFlag])
+		filePath := filepath.Join(solutionsDir, file+languages[langFlag])
 		if !fileExists(filePath) {
-			return "", fmt.Errorf("'%s' solution file not found! Searched in '%s'\n", langFlag, filepath.Join(config.EVMR_LEVELS_DIR, solutionDir))
+			return "", fmt.Errorf("'%s' solution file not found! Searched in '%s'\n", langFlag, solutionsDir)
 		}
 	}
 
 	// Check general existence of solution files
-	var existingFiles []string
-	for lang, ext := range languages {
-		filePath := filepath.Join(config.EVMR_LEVELS_DIR, solutionDir, file+ext)
-		if fileExists(filePath) {
-			existingFiles = append(existingFiles, lang)
-		}
-	}
+	existingFiles := SolutionTypes(solutionsDir, file)
 
 	// Handle cases with no solution files or multiple solution files
 	if len(existingFiles) == 0 {
-		return "", fmt.Errorf("No solution file found! Searched in '%s'\nRun 'evmr start <level>' first or submit pure bytecode with -b <bytecode>\n", filepath.Join(config.EVMR_LEVELS_DIR, solutionDir))
+		return "", fmt.Errorf("No solution file found! Searched in '%s'\nRun 'evmr start <level>' first or submit pure bytecode with -b <bytecode>\n", solutionsDir)
 	} else if langFlag == "" && len(existingFiles) > 1 {
 		return "", fmt.Errorf("More than one solution file found!\nDelete a solution file or use --lang to choose which one to validate.\n")
 	}
@@ -399,6 +494,18 @@ func getSolutionType(file string, langFlag string) (string, error) {
 	return langFlag, nil
 }
 
+// returns the languages of the solution files of a level, e.g. [sol huff]
+func SolutionTypes(solutionsDir string, file string) []string {
+	var types []string
+	for _, lang := range levelLanguages {
+		if fileExists(solutionPath(solutionsDir, file, lang)) {
+			types = append(types, lang)
+		}
+	}
+
+	return types
+}
+
 // checks if a file exists
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
@@ -430,18 +537,14 @@ func sanitizeBytecode(bytecode string) (string, error) {
 	return bytecode, nil
 }
 
-func CheckMinTerminalWidth() error {
-	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+// returns the size of the terminal, or 0, 0 if stdout is not a terminal
+func TerminalSize() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
 	if err != nil {
-		return fmt.Errorf("error getting terminal size: %v", err)
-	}
-
-	minTerminalWidth := 80
-	if width < minTerminalWidth {
-		return fmt.Errorf("Terminal width is too small (%d < %d).\nPlease resize your terminal window.\n", width, minTerminalWidth)
+		return 0, 0
 	}
 
-	return nil
+	return width, height
 }
 
 func IsValidEthereumAddress(address string) bool {
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestStreamTestReturnsAfterCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake forge is a shell script")
+	}
+
+	binDir := t.TempDir()
+	script := "#!/bin/sh\necho first\necho second\nexec sleep 30\n"
+	if err := os.WriteFile(filepath.Join(binDir, "forge"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lines := make(chan string)
+	returned := make(chan struct{})
+	go func() {
+		StreamTest(ctx, t.TempDir(), "AverageTestBase", false, lines)
+		close(returned)
+	}()
+
+	// read one line, then stop reading like the TUI does after quitting
+	if line := <-lines; line != "first" {
+		t.Errorf("first line = %q, want %q", line, "first")
+	}
+	// give StreamTest time to block on sending the second line
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-returned:
+	case <-time.After(10 * time.Second):
+		t.Fatal("StreamTest blocked after the tests were canceled")
+	}
+}
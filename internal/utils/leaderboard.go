@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// fetches the gas or size leaderboard of a level, best submission first
+func FetchLeaderboard(server string, field string, levelId string) ([]SubmissionData, error) {
+	url := fmt.Sprintf("%ssubmissions/leaderboard/%s/%s", server, field, levelId)
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("error making GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http request failed with status: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %v", err)
+	}
+
+	var leaderboard []SubmissionData
+	if err := json.Unmarshal(body, &leaderboard); err != nil {
+		return nil, fmt.Errorf("error decoding JSON response: %v", err)
+	}
+
+	return leaderboard, nil
+}
+
+// returns the position of the user in a leaderboard, starting at 1, or 0 if the user is not on it
+func LeaderboardRank(config Config, leaderboard []SubmissionData) int {
+	for i, submission := range leaderboard {
+		if config.EVMR_ID != "" && strconv.Itoa(submission.UserId) == config.EVMR_ID {
+			return i + 1
+		}
+		if config.EVMR_ID == "" && config.EVMR_NAME != "" && submission.Username == config.EVMR_NAME {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// Returns the best rank of the user on the gas and size leaderboards of each solved level, keyed like levels.
+// Levels without a rank are left out.
+func GetRanks(config Config, levels map[string]Level, solved map[string]string) map[string]int {
+	ranks := make(map[string]int)
+	servers := packServers(config)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for key, level := range levels {
+		if solved[key] == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(key string, level Level) {
+			defer wg.Done()
+
+			best := 0
+			for _, field := range []string{"gas", "size"} {
+				// if the request fails for some reason, the level just has no rank
+				leaderboard, err := FetchLeaderboard(servers[level.Pack], field, level.ID)
+				if err != nil {
+					continue
+				}
+
+				if rank := LeaderboardRank(config, leaderboard); rank > 0 && (best == 0 || rank < best) {
+					best = rank
+				}
+			}
+
+			if best > 0 {
+				mu.Lock()
+				ranks[key] = best
+				mu.Unlock()
+			}
+		}(key, level)
+	}
+
+	wg.Wait()
+
+	return ranks
+}
@@ -0,0 +1,328 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// languages a level can support, by file extension
+var levelLanguages = []string{"sol", "yul", "vy", "huff"}
+
+// contract and file names are used in paths, URLs and solution files
+var levelNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// schema of levels.toml
+type levelsFileSchema struct {
+	Levels []levelSchema `toml:"levels"`
+}
+
+// schema of a [[levels]] entry. id, file, contract and type are required, all other fields are optional.
+type levelSchema struct {
+	ID          string    `toml:"id"`
+	File        string    `toml:"file"`
+	Contract    string    `toml:"contract"`
+	Type        string    `toml:"type"`
+	Description string    `toml:"description"`
+	Difficulty  string    `toml:"difficulty"`
+	Tags        []string  `toml:"tags"`
+	Author      string    `toml:"author"`
+	ReleaseDate time.Time `toml:"release_date"`
+	Languages   []string  `toml:"languages"`
+	Hints       []string  `toml:"hints"`
+}
+
+// an error in levels.toml, e.g. 'levels.toml:12: field 'id' is missing'
+type levelsFileError struct {
+	path  string
+	line  int
+	field string
+	msg   string
+}
+
+func (e *levelsFileError) Error() string {
+	location := e.path
+	if e.line > 0 {
+		location += fmt.Sprintf(":%d", e.line)
+	}
+
+	if e.field != "" {
+		return fmt.Sprintf("%s: field '%s' %s", location, e.field, e.msg)
+	}
+	return fmt.Sprintf("%s: %s", location, e.msg)
+}
+
+// loads the levels.toml of a pack, keyed by lowercased contract name
+func loadPackLevels(pack LevelPack) (map[string]Level, error) {
+	path := filepath.Join(pack.Dir, levelsFile)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if pack.Name != OfficialPack {
+			return nil, fmt.Errorf("'%s' not found", path)
+		}
+		// print error to run evm-runners init first
+		return nil, fmt.Errorf("No config file found. Please run 'evmr init' first!\n")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading '%s': %v", path, err)
+	}
+
+	return parseLevels(path, data, pack.Name)
+}
+
+// decodes and validates the contents of a levels.toml file
+func parseLevels(path string, data []byte, packName string) (map[string]Level, error) {
+	positions := levelPositions(data)
+
+	var file levelsFileSchema
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&file); err != nil {
+		return nil, decodeLevelsError(path, data, positions, err)
+	}
+
+	if len(file.Levels) == 0 {
+		return nil, &levelsFileError{path: path, msg: "no levels found, add them as [[levels]] tables"}
+	}
+
+	var errs []error
+	ids := make(map[string]int)
+	contracts := make(map[string]int)
+	levels := make(map[string]Level)
+
+	for i, l := range file.Levels {
+		pos := positions.level(i)
+		fail := func(field string, format string, args ...interface{}) {
+			errs = append(errs, &levelsFileError{path: path, line: pos.line(field), field: field, msg: fmt.Sprintf(format, args...)})
+		}
+
+		// required fields
+		required := []struct{ field, value string }{{"id", l.ID}, {"file", l.File}, {"contract", l.Contract}, {"type", l.Type}}
+		for _, r := range required {
+			if strings.TrimSpace(r.value) == "" {
+				fail(r.field, "is missing")
+			}
+		}
+
+		if l.Contract != "" && !levelNameRegex.MatchString(l.Contract) {
+			fail("contract", "must be a valid contract name, got '%s'", l.Contract)
+		}
+		if l.File != "" && !levelNameRegex.MatchString(l.File) {
+			fail("file", "must be a file name without extension, got '%s'", l.File)
+		}
+		for _, lang := range l.Languages {
+			if !contains(levelLanguages, lang) {
+				fail("languages", "contains unknown language '%s', use %s", lang, strings.Join(levelLanguages, ", "))
+			}
+		}
+
+		if first, ok := ids[l.ID]; ok && l.ID != "" {
+			fail("id", "has the same value '%s' as the level on line %d", l.ID, positions.level(first).header)
+		}
+		key := strings.ToLower(l.Contract)
+		if first, ok := contracts[key]; ok && key != "" {
+			fail("contract", "has the same value '%s' as the level on line %d", l.Contract, positions.level(first).header)
+		}
+		ids[l.ID] = i
+		contracts[key] = i
+
+		levels[key] = Level{
+			ID:          l.ID,
+			File:        l.File,
+			Contract:    l.Contract,
+			Type:        l.Type,
+			Description: l.Description,
+			Difficulty:  l.Difficulty,
+			Tags:        l.Tags,
+			Author:      l.Author,
+			ReleaseDate: l.ReleaseDate,
+			Languages:   l.Languages,
+			Hints:       l.Hints,
+			Pack:        packName,
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool {
+			return errs[i].(*levelsFileError).line < errs[j].(*levelsFileError).line
+		})
+		return nil, errors.Join(errs...)
+	}
+
+	return levels, nil
+}
+
+// returns true if the level has a template for the language, e.g. sol
+func (l Level) SupportsLanguage(lang string) bool {
+	return len(l.Languages) == 0 || contains(l.Languages, lang)
+}
+
+// turns an error of the TOML decoder into an error with the line and field
+func decodeLevelsError(path string, data []byte, positions levelsFilePositions, err error) error {
+	// type errors of the decoder have no position or a confusing message, so find the field with the wrong type ourselves
+	var raw map[string]interface{}
+	if toml.Unmarshal(data, &raw) == nil {
+		if typeErr := checkLevelTypes(path, raw, positions); typeErr != nil {
+			return typeErr
+		}
+	}
+
+	var decodeErr *toml.DecodeError
+	if errors.As(err, &decodeErr) {
+		line, _ := decodeErr.Position()
+		return &levelsFileError{path: path, line: line, msg: strings.TrimPrefix(decodeErr.Error(), "toml: ")}
+	}
+
+	var strictErr *toml.StrictMissingError
+	if errors.As(err, &strictErr) {
+		var errs []error
+		for _, e := range strictErr.Errors {
+			line, _ := e.Position()
+			key := e.Key()
+			errs = append(errs, &levelsFileError{path: path, line: line, field: key[len(key)-1], msg: "is not a known field"})
+		}
+		return errors.Join(errs...)
+	}
+
+	return &levelsFileError{path: path, msg: strings.TrimPrefix(err.Error(), "toml: ")}
+}
+
+// returns an error for the first field of raw levels.toml contents that doesn't match the type of levelSchema
+func checkLevelTypes(path string, raw map[string]interface{}, positions levelsFilePositions) error {
+	levels, ok := raw["levels"].([]interface{})
+	if !ok {
+		return &levelsFileError{path: path, msg: "'levels' must be an array of tables, add levels as [[levels]]"}
+	}
+
+	schema := reflect.TypeOf(levelSchema{})
+	for i, entry := range levels {
+		pos := positions.level(i)
+
+		level, ok := entry.(map[string]interface{})
+		if !ok {
+			return &levelsFileError{path: path, line: pos.header, msg: fmt.Sprintf("level %d must be a table", i+1)}
+		}
+
+		for j := 0; j < schema.NumField(); j++ {
+			field := schema.Field(j)
+			name := strings.Split(field.Tag.Get("toml"), ",")[0]
+
+			value, ok := level[name]
+			if !ok {
+				continue
+			}
+
+			if expected := checkLevelType(value, field.Type); expected != "" {
+				return &levelsFileError{path: path, line: pos.line(name), field: name, msg: "must be " + expected}
+			}
+		}
+	}
+
+	return nil
+}
+
+// returns the expected type if the value can't be decoded into t
+func checkLevelType(value interface{}, t reflect.Type) string {
+	switch t {
+	case reflect.TypeOf(""):
+		if _, ok := value.(string); !ok {
+			return "a string"
+		}
+	case reflect.TypeOf([]string{}):
+		list, ok := value.([]interface{})
+		if !ok {
+			return "a list of strings, e.g. [\"a\", \"b\"]"
+		}
+		for _, item := range list {
+			if _, ok := item.(string); !ok {
+				return "a list of strings, e.g. [\"a\", \"b\"]"
+			}
+		}
+	case reflect.TypeOf(time.Time{}):
+		switch value.(type) {
+		case toml.LocalDate, toml.LocalDateTime, time.Time:
+		default:
+			return "a date without quotes, e.g. 2023-04-01"
+		}
+	}
+
+	return ""
+}
+
+// line numbers of the [[levels]] tables of a levels.toml file and their fields
+type levelsFilePositions []levelPosition
+
+type levelPosition struct {
+	// line of the [[levels]] header
+	header int
+	fields map[string]int
+}
+
+var (
+	levelsHeaderRegex = regexp.MustCompile(`^\s*\[\[\s*"?levels"?\s*\]\]`)
+	tableHeaderRegex  = regexp.MustCompile(`^\s*\[`)
+	keyRegex          = regexp.MustCompile(`^\s*"?([A-Za-z0-9_-]+)"?\s*=`)
+)
+
+// finds the line numbers of the levels in a levels.toml file. Levels that are not written as [[levels]] tables have no line numbers.
+func levelPositions(data []byte) levelsFilePositions {
+	var positions levelsFilePositions
+	var current *levelPosition
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+
+		switch {
+		case levelsHeaderRegex.MatchString(text):
+			positions = append(positions, levelPosition{header: line, fields: make(map[string]int)})
+			current = &positions[len(positions)-1]
+		case tableHeaderRegex.MatchString(text):
+			current = nil
+		case current != nil:
+			if match := keyRegex.FindStringSubmatch(text); match != nil {
+				if _, ok := current.fields[match[1]]; !ok {
+					current.fields[match[1]] = line
+				}
+			}
+		}
+	}
+
+	return positions
+}
+
+func (p levelsFilePositions) level(i int) levelPosition {
+	if i < len(p) {
+		return p[i]
+	}
+	return levelPosition{}
+}
+
+// returns the line of a field, or the line of the [[levels]] header if the field is missing
+func (p levelPosition) line(field string) int {
+	if line, ok := p.fields[field]; ok {
+		return line
+	}
+	return p.header
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
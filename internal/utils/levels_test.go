@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLevels(t *testing.T) {
+	data := `[[levels]]
+id = "1"
+file = "Average"
+contract = "Average"
+type = "solidity"
+languages = ["sol", "huff"]
+release_date = 2023-04-01
+`
+
+	levels, err := parseLevels("levels.toml", []byte(data), "community")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	level, ok := levels["average"]
+	if !ok {
+		t.Fatalf("levels are not keyed by the lowercased contract name: %v", levels)
+	}
+	if level.ID != "1" || level.Pack != "community" || !level.SupportsLanguage("huff") || level.SupportsLanguage("vy") {
+		t.Errorf("unexpected level %+v", level)
+	}
+	if level.ReleaseDate.Year() != 2023 {
+		t.Errorf("release date = %v, want 2023-04-01", level.ReleaseDate)
+	}
+}
+
+func TestParseLevelsErrorLines(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "missing fields",
+			data: "[[levels]]\nid = \"1\"\nfile = \"Average\"\ncontract = \"Average\"\ntype = \"solidity\"\n\n[[levels]]\nid = \"2\"\nfile = \"Sqrt\"\n",
+			want: []string{
+				"levels.toml:7: field 'contract' is missing",
+				"levels.toml:7: field 'type' is missing",
+			},
+		},
+		{
+			name: "invalid values",
+			data: "[[levels]]\nid = \"1\"\nfile = \"Average.sol\"\ncontract = \"Average\"\ntype = \"solidity\"\nlanguages = [\"sol\", \"rust\"]\n",
+			want: []string{
+				"levels.toml:3: field 'file' must be a file name without extension, got 'Average.sol'",
+				"levels.toml:6: field 'languages' contains unknown language 'rust'",
+			},
+		},
+		{
+			name: "duplicates",
+			data: "[[levels]]\nid = \"1\"\nfile = \"Average\"\ncontract = \"Average\"\ntype = \"solidity\"\n\n[[levels]]\nid = \"1\"\nfile = \"Average2\"\ncontract = \"average\"\ntype = \"solidity\"\n",
+			want: []string{
+				"levels.toml:8: field 'id' has the same value '1' as the level on line 1",
+				"levels.toml:10: field 'contract' has the same value 'average' as the level on line 1",
+			},
+		},
+		{
+			name: "wrong type",
+			data: "[[levels]]\nid = 1\nfile = \"Average\"\ncontract = \"Average\"\ntype = \"solidity\"\n",
+			want: []string{"levels.toml:2: field 'id' must be a string"},
+		},
+		{
+			name: "quoted date",
+			data: "[[levels]]\nid = \"1\"\nfile = \"Average\"\ncontract = \"Average\"\ntype = \"solidity\"\nrelease_date = \"2023-04-01\"\n",
+			want: []string{"levels.toml:6: field 'release_date' must be a date without quotes"},
+		},
+		{
+			name: "unknown field",
+			data: "[[levels]]\nid = \"1\"\nfile = \"Average\"\ncontract = \"Average\"\ntype = \"solidity\"\nauthors = \"me\"\n",
+			want: []string{"levels.toml:6: field 'authors' is not a known field"},
+		},
+		{
+			name: "syntax error",
+			data: "[[levels]]\nid = \"1\"\nfile = \"Average\n",
+			want: []string{"levels.toml:3: "},
+		},
+		{
+			name: "no levels",
+			data: "# no levels yet\n",
+			want: []string{"levels.toml: no levels found"},
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := parseLevels("levels.toml", []byte(tt.data), OfficialPack)
+		if err == nil {
+			t.Errorf("%s: parseLevels succeeded", tt.name)
+			continue
+		}
+
+		lines := strings.Split(err.Error(), "\n")
+		if len(lines) != len(tt.want) {
+			t.Errorf("%s: got %d errors, want %d:\n%v", tt.name, len(lines), len(tt.want), err)
+			continue
+		}
+		for i, want := range tt.want {
+			if !strings.HasPrefix(lines[i], want) {
+				t.Errorf("%s: error %d = %q, want prefix %q", tt.name, i+1, lines[i], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EVMR_VERSION is the installed evm-runners version written by evmrup, so the
+// config format is versioned with a separate key
+const configVersionKey = "EVMR_CONFIG_VERSION"
+
+// current version of the config format
+var configVersion = len(configMigrations)
+
+type configMigration struct {
+	description string
+	// migrates the settings of a config file. Keys are lowercase, as returned by viper.
+	migrate func(settings map[string]interface{}, profile string)
+}
+
+// migrations of the config format, in order. Migration i upgrades a config file from version i to i+1.
+// Never change or reorder existing migrations, only append new ones.
+var configMigrations = []configMigration{
+	{
+		description: "add the default server URL",
+		migrate: func(settings map[string]interface{}, profile string) {
+			server, _ := settings["evmr_server"].(string)
+
+			// profiles inherit the server of the default profile
+			if server == "" && profile == DefaultProfile {
+				settings["evmr_server"] = DefaultServer
+			} else if server != "" && !strings.HasSuffix(server, "/") {
+				settings["evmr_server"] = server + "/"
+			}
+		},
+	},
+	{
+		description: "remove empty keys",
+		migrate: func(settings map[string]interface{}, profile string) {
+			// empty keys in a profile would override the values of the default profile
+			for key, value := range settings {
+				if str, ok := value.(string); ok && str == "" {
+					delete(settings, key)
+				}
+			}
+		},
+	},
+}
+
+// migrates a config file to the current config version. The old file is backed up first.
+// Returns an error if the config file was written by a newer version of evm-runners.
+func migrateConfigFile(path string, profile string) error {
+	v, err := readEnvFile(path)
+	if err != nil {
+		return err
+	}
+
+	version := v.GetInt(configVersionKey)
+	if version > configVersion {
+		return fmt.Errorf("The config file '%s' has version %d, but this version of evm-runners only supports up to version %d.\nPlease update evm-runners by running 'evmrup'.\n", path, version, configVersion)
+	}
+
+	if version == configVersion {
+		return nil
+	}
+
+	settings := v.AllSettings()
+
+	// new config files are empty and don't need a backup
+	backupPath := ""
+	if len(settings) > 0 {
+		backupPath = fmt.Sprintf("%s.v%d.bak", path, version)
+		if err := backupConfigFile(path, backupPath); err != nil {
+			return fmt.Errorf("error backing up config file before migrating: %v", err)
+		}
+	}
+
+	for _, migration := range configMigrations[version:] {
+		migration.migrate(settings, profile)
+	}
+
+	settings[strings.ToLower(configVersionKey)] = configVersion
+
+	if err := writeEnvFile(path, settings); err != nil {
+		return fmt.Errorf("error migrating config file '%s': %v", path, err)
+	}
+
+	if backupPath != "" {
+		fmt.Printf("Migrated config file '%s' from version %d to %d. A backup was saved to '%s'.\n\n", path, version, configVersion, backupPath)
+	}
+
+	return nil
+}
+
+// copies a config file without its credentials, which are kept in the credential store or the migrated file.
+// The copy is only readable by the user.
+func backupConfigFile(src string, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		name, _, _ := strings.Cut(strings.TrimSpace(line), "=")
+		if key, err := GetConfigKey(strings.TrimSpace(name)); err == nil && key.Credential {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return os.WriteFile(dst, []byte(strings.Join(lines, "")), credentialFileMode)
+}
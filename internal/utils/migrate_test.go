@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateConfigFile(t *testing.T) {
+	home := setupTestHome(t)
+	path := filepath.Join(home, configFile)
+
+	// version 0 config files have no version, no server and empty keys
+	v0 := "EVMR_TOKEN=secret-token\nEVMR_ID=5\nEVMR_NAME=\nEVMR_LEVELS_DIR=/levels\n"
+	if err := os.WriteFile(path, []byte(v0), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateConfigFile(path, DefaultProfile); err != nil {
+		t.Fatalf("migrateConfigFile() error = %v", err)
+	}
+
+	v, err := readEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.GetInt(configVersionKey); got != configVersion {
+		t.Errorf("%s = %d, want %d", configVersionKey, got, configVersion)
+	}
+	if got := v.GetString("EVMR_SERVER"); got != DefaultServer {
+		t.Errorf("EVMR_SERVER = %q, want %q", got, DefaultServer)
+	}
+	if v.IsSet("EVMR_NAME") {
+		t.Errorf("empty key EVMR_NAME was kept")
+	}
+	if got := v.GetString("EVMR_ID"); got != "5" {
+		t.Errorf("EVMR_ID = %q, want 5", got)
+	}
+
+	backup, err := os.ReadFile(path + ".v0.bak")
+	if err != nil {
+		t.Fatalf("no backup: %v", err)
+	}
+	if strings.Contains(string(backup), "secret-token") {
+		t.Errorf("backup contains the auth token:\n%s", backup)
+	}
+	if !strings.Contains(string(backup), "EVMR_ID=5\n") || !strings.Contains(string(backup), "EVMR_LEVELS_DIR=/levels\n") {
+		t.Errorf("backup is missing settings:\n%s", backup)
+	}
+
+	// migrating again does nothing
+	if err := migrateConfigFile(path, DefaultProfile); err != nil {
+		t.Fatalf("migrateConfigFile() error = %v", err)
+	}
+}
+
+func TestMigrateProfileKeepsInheritedServer(t *testing.T) {
+	home := setupTestHome(t)
+	path := filepath.Join(home, profilesDir, "staging"+configFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("EVMR_SOLUTIONS_DIR=\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateConfigFile(path, "staging"); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := readEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// profiles inherit the server of the default profile, so none is added
+	if v.IsSet("EVMR_SERVER") || v.IsSet("EVMR_SOLUTIONS_DIR") {
+		t.Errorf("profile config = %v", v.AllSettings())
+	}
+}
+
+func TestMigrateConfigFileFromNewerVersion(t *testing.T) {
+	home := setupTestHome(t)
+	path := filepath.Join(home, configFile)
+	if err := os.WriteFile(path, []byte("EVMR_CONFIG_VERSION=99\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateConfigFile(path, DefaultProfile); err == nil {
+		t.Errorf("migrateConfigFile() accepted a config file of a newer version")
+	}
+}
@@ -0,0 +1,309 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// the levels directory of EVMR_LEVELS_DIR, which is always installed
+	OfficialPack = "official"
+
+	packsFile = "packs.toml"
+	packsDir  = "packs"
+)
+
+var packNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// a set of levels installed side by side with the official levels, e.g. a community or training pack
+type LevelPack struct {
+	Name string `mapstructure:"-"`
+	// directory of the levels, containing levels.toml
+	Dir string `mapstructure:"dir"`
+	// git repository, directory or tarball the pack was installed from
+	Source string `mapstructure:"source"`
+	Ref    string `mapstructure:"ref"`
+	// server of the pack, EVMR_SERVER is used if empty
+	Server string `mapstructure:"server"`
+}
+
+// returns the path of the packs file, e.g. ~/.evm-runners/packs.toml
+func packsFilePath() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, packsFile), nil
+}
+
+// returns all installed level packs, the official pack first and the others sorted by name
+func LoadPacks(config Config) ([]LevelPack, error) {
+	packs := []LevelPack{{Name: OfficialPack, Dir: config.EVMR_LEVELS_DIR}}
+
+	installed, err := loadInstalledPacks()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range installed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		packs = append(packs, installed[name])
+	}
+
+	return packs, nil
+}
+
+// returns the level pack with the given name
+func GetPack(config Config, name string) (LevelPack, error) {
+	packs, err := LoadPacks(config)
+	if err != nil {
+		return LevelPack{}, err
+	}
+
+	for _, pack := range packs {
+		if pack.Name == strings.ToLower(name) {
+			return pack, nil
+		}
+	}
+
+	return LevelPack{}, fmt.Errorf("Level pack '%s' is not installed. Run 'evmr pack list' to see the installed packs.\n", name)
+}
+
+// reads the packs file, which lists the packs other than the official one
+func loadInstalledPacks() (map[string]LevelPack, error) {
+	packs := make(map[string]LevelPack)
+
+	path, err := packsFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	if !fileExists(path) {
+		return packs, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("toml")
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("error reading '%s': %v", path, err)
+	}
+
+	var file struct {
+		Packs map[string]LevelPack `mapstructure:"packs"`
+	}
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, fmt.Errorf("error reading '%s': %v", path, err)
+	}
+
+	for name, pack := range file.Packs {
+		pack.Name = name
+		packs[name] = pack
+	}
+
+	return packs, nil
+}
+
+// overwrites the packs file
+func writeInstalledPacks(packs map[string]LevelPack) error {
+	path, err := packsFilePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating config directory: %v", err)
+	}
+
+	settings := make(map[string]interface{})
+	for name, pack := range packs {
+		settings[name] = map[string]interface{}{
+			"dir":    pack.Dir,
+			"source": pack.Source,
+			"ref":    pack.Ref,
+			"server": pack.Server,
+		}
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("toml")
+	v.Set("packs", settings)
+
+	if err := v.WriteConfig(); err != nil {
+		return fmt.Errorf("failed to write '%s': %v", path, err)
+	}
+
+	return nil
+}
+
+// returns the directory a pack is installed into if no directory is given, e.g. ~/.evm-runners/packs/<name>
+func DefaultPackDir(name string) (string, error) {
+	dataDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, packsDir, name), nil
+}
+
+// installs a level pack from a source (see InstallLevels) and registers it
+func AddPack(config Config, pack LevelPack) error {
+	pack.Name = strings.ToLower(pack.Name)
+	if !packNameRegex.MatchString(pack.Name) {
+		return fmt.Errorf("Invalid pack name '%s'. Use lowercase letters, numbers, '-' and '_'.\n", pack.Name)
+	}
+
+	installed, err := loadInstalledPacks()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := installed[pack.Name]; ok || pack.Name == OfficialPack {
+		return fmt.Errorf("Level pack '%s' is already installed.\n", pack.Name)
+	}
+
+	if pack.Dir == "" {
+		if pack.Dir, err = DefaultPackDir(pack.Name); err != nil {
+			return err
+		}
+	}
+
+	if pack.Dir, err = filepath.Abs(pack.Dir); err != nil {
+		return fmt.Errorf("error getting absolute path for '%s': %v", pack.Dir, err)
+	}
+
+	if fileExists(pack.Dir) {
+		return fmt.Errorf("'%s' already exists. Remove it or choose another directory with '--dir'.\n", pack.Dir)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(pack.Dir), 0755); err != nil {
+		return err
+	}
+
+	if err := InstallLevels(pack.Source, pack.Ref, pack.Dir); err != nil {
+		return err
+	}
+
+	if _, err := loadPackLevels(pack); err != nil {
+		os.RemoveAll(pack.Dir)
+		return fmt.Errorf("'%s' is not a valid level pack: %v", pack.Source, err)
+	}
+
+	installed[pack.Name] = pack
+
+	return writeInstalledPacks(installed)
+}
+
+// unregisters a level pack and returns it. The pack directory is not deleted.
+func RemovePack(name string) (LevelPack, error) {
+	name = strings.ToLower(name)
+	if name == OfficialPack {
+		return LevelPack{}, fmt.Errorf("The official levels can't be removed.\n")
+	}
+
+	installed, err := loadInstalledPacks()
+	if err != nil {
+		return LevelPack{}, err
+	}
+
+	pack, ok := installed[name]
+	if !ok {
+		return LevelPack{}, fmt.Errorf("Level pack '%s' is not installed.\n", name)
+	}
+
+	delete(installed, name)
+
+	return pack, writeInstalledPacks(installed)
+}
+
+// returns the config used for the levels of a pack, with the levels directory, server and solutions directory of the pack
+func PackConfig(config Config, name string) (Config, error) {
+	if name == "" || name == OfficialPack {
+		return config, nil
+	}
+
+	pack, err := GetPack(config, name)
+	if err != nil {
+		return config, err
+	}
+
+	config.EVMR_LEVELS_DIR = pack.Dir
+	if pack.Server != "" {
+		config = serverConfig(config, pack.Server)
+	}
+
+	// solutions of a pack are kept in a subdirectory of a custom solutions directory
+	if config.EVMR_SOLUTIONS_DIR != "" {
+		config.EVMR_SOLUTIONS_DIR = filepath.Join(config.EVMR_SOLUTIONS_DIR, pack.Name)
+	}
+
+	return config, nil
+}
+
+// returns the config used to talk to a server. The credentials of the config were issued by EVMR_SERVER,
+// so they are not sent to other servers and a token refresh can't overwrite them.
+func serverConfig(config Config, server string) Config {
+	if server == config.EVMR_SERVER {
+		return config
+	}
+
+	config.EVMR_SERVER = server
+	config.EVMR_TOKEN = ""
+	config.EVMR_REFRESH_TOKEN = ""
+	config.credentialsLoaded = true
+
+	return config
+}
+
+// returns the key of a level from a name given by the user. Names can be namespaced by their pack, e.g. 'official/average',
+// or just the level, e.g. 'average', which selects the official level or the only level with that name.
+func ResolveLevel(levels map[string]Level, name string) (string, error) {
+	name = strings.ToLower(name)
+
+	if strings.Contains(name, "/") {
+		if _, ok := levels[name]; ok {
+			return name, nil
+		}
+		return "", fmt.Errorf("level %s does not exist", name)
+	}
+
+	if _, ok := levels[OfficialPack+"/"+name]; ok {
+		return OfficialPack + "/" + name, nil
+	}
+
+	var matches []string
+	for key := range levels {
+		if strings.HasSuffix(key, "/"+name) {
+			matches = append(matches, key)
+		}
+	}
+	sort.Strings(matches)
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("level %s does not exist", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("level %s exists in several packs, use one of: %s\n", name, strings.Join(matches, ", "))
+	}
+}
+
+// returns the name of a level to show to the user. Official levels are shown without their pack.
+func LevelName(key string) string {
+	return strings.TrimPrefix(key, OfficialPack+"/")
+}
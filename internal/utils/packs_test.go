@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveLevel(t *testing.T) {
+	levels := map[string]Level{
+		"official/average":  {Pack: OfficialPack},
+		"community/average": {Pack: "community"},
+		"community/sqrt":    {Pack: "community"},
+		"training/sqrt":     {Pack: "training"},
+		"training/fib":      {Pack: "training"},
+	}
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		// official levels are preferred over levels of other packs
+		{name: "average", want: "official/average"},
+		{name: "Average", want: "official/average"},
+		{name: "community/average", want: "community/average"},
+		{name: "fib", want: "training/fib"},
+		{name: "sqrt", wantErr: "community/sqrt, training/sqrt"},
+		{name: "missing", wantErr: "does not exist"},
+		{name: "official/fib", wantErr: "does not exist"},
+	}
+
+	for _, tt := range tests {
+		got, err := ResolveLevel(levels, tt.name)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ResolveLevel(%q) error = %v, want error containing %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ResolveLevel(%q) error = %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ResolveLevel(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestServerConfigDropsCredentials(t *testing.T) {
+	config := Config{
+		EVMR_SERVER:        "https://api.evmr.sh/",
+		EVMR_TOKEN:         "token",
+		EVMR_REFRESH_TOKEN: "refresh",
+	}
+
+	same := serverConfig(config, config.EVMR_SERVER)
+	if same.EVMR_TOKEN != "token" || same.EVMR_REFRESH_TOKEN != "refresh" {
+		t.Errorf("credentials of EVMR_SERVER were dropped")
+	}
+
+	other := serverConfig(config, "https://pack.example.com/")
+	if other.EVMR_SERVER != "https://pack.example.com/" {
+		t.Errorf("EVMR_SERVER = %q, want the pack server", other.EVMR_SERVER)
+	}
+	if other.EVMR_TOKEN != "" || other.EVMR_REFRESH_TOKEN != "" {
+		t.Errorf("credentials were passed to another server")
+	}
+}
@@ -0,0 +1,263 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultProfile = "default"
+	profilesDir    = "profiles"
+	profileKey     = "EVMR_PROFILE"
+)
+
+var profileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// profile selected with the global --profile flag
+var profileFlag string
+
+// sets the profile selected with the global --profile flag
+func SetProfile(profile string) {
+	profileFlag = profile
+}
+
+// returns the active profile. The --profile flag takes precedence over the EVMR_PROFILE environment variable,
+// which takes precedence over the profile selected with 'evmr profile use'.
+func ActiveProfile() (string, error) {
+	profile := profileFlag
+
+	if profile == "" {
+		profile = os.Getenv(profileKey)
+	}
+
+	if profile == "" {
+		envFilePath, err := ConfigFilePath()
+		if err != nil {
+			return "", err
+		}
+
+		// without a config file, there is no selected profile
+		if _, err := os.Stat(envFilePath); os.IsNotExist(err) {
+			return DefaultProfile, nil
+		}
+
+		v, err := readEnvFile(envFilePath)
+		if err != nil {
+			return "", err
+		}
+		profile = v.GetString(profileKey)
+	}
+
+	if profile == "" {
+		return DefaultProfile, nil
+	}
+
+	if err := validateProfileName(profile); err != nil {
+		return "", err
+	}
+
+	return profile, nil
+}
+
+// returns the path of the config file of a profile, e.g. ~/.evm-runners/profiles/staging.env
+func ProfileFilePath(profile string) (string, error) {
+	if profile == DefaultProfile {
+		return ConfigFilePath()
+	}
+
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, profilesDir, profile+configFile), nil
+}
+
+// returns the names of all profiles, starting with the default profile
+func ListProfiles() ([]string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(configDir, profilesDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading profiles directory: %v", err)
+	}
+
+	var profiles []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, configFile) {
+			continue
+		}
+		profiles = append(profiles, strings.TrimSuffix(name, configFile))
+	}
+	sort.Strings(profiles)
+
+	return append([]string{DefaultProfile}, profiles...), nil
+}
+
+// selects the profile that is used when neither --profile nor EVMR_PROFILE is set
+func UseProfile(profile string) error {
+	if err := validateProfileName(profile); err != nil {
+		return err
+	}
+
+	if profile != DefaultProfile {
+		if exists, err := profileExists(profile); err != nil {
+			return err
+		} else if !exists {
+			return fmt.Errorf("Profile '%s' does not exist. Run 'evmr profile add %s' to create it.\n", profile, profile)
+		}
+	} else {
+		profile = ""
+	}
+
+	envFilePath, err := ConfigFilePath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(envFilePath); os.IsNotExist(err) {
+		return fmt.Errorf("No config file found. Please run 'evmr init' first!\n")
+	}
+
+	v, err := readEnvFile(envFilePath)
+	if err != nil {
+		return err
+	}
+
+	v.Set(profileKey, profile)
+	if err := v.WriteConfig(); err != nil {
+		return fmt.Errorf("failed to write config: %v", err)
+	}
+
+	return nil
+}
+
+// creates a new profile. Empty fields of the config are inherited from the default profile.
+func AddProfile(profile string, config Config) error {
+	if err := validateProfileName(profile); err != nil {
+		return err
+	}
+
+	if profile == DefaultProfile {
+		return fmt.Errorf("The default profile always exists.\n")
+	}
+
+	if exists, err := profileExists(profile); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("Profile '%s' already exists.\n", profile)
+	}
+
+	profilePath, err := ProfileFilePath(profile)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(profilePath), 0755); err != nil {
+		return fmt.Errorf("error creating profiles directory: %v", err)
+	}
+
+	f, err := os.OpenFile(profilePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, credentialFileMode)
+	if err != nil {
+		return fmt.Errorf("error creating profile config file: %v", err)
+	}
+	f.Close()
+
+	v, err := readEnvFile(profilePath)
+	if err != nil {
+		return err
+	}
+
+	v.Set(configVersionKey, configVersion)
+	if config.EVMR_SERVER != "" {
+		v.Set("EVMR_SERVER", config.EVMR_SERVER)
+	}
+	if config.EVMR_LEVELS_DIR != "" {
+		v.Set("EVMR_LEVELS_DIR", config.EVMR_LEVELS_DIR)
+	}
+
+	if err := v.WriteConfig(); err != nil {
+		return fmt.Errorf("failed to write config: %v", err)
+	}
+
+	return nil
+}
+
+// removes a profile. If the profile is selected, the default profile is selected instead.
+func RemoveProfile(profile string) error {
+	if err := validateProfileName(profile); err != nil {
+		return err
+	}
+
+	if profile == DefaultProfile {
+		return fmt.Errorf("The default profile can't be removed.\n")
+	}
+
+	if exists, err := profileExists(profile); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("Profile '%s' does not exist.\n", profile)
+	}
+
+	profilePath, err := ProfileFilePath(profile)
+	if err != nil {
+		return err
+	}
+
+	// remove the auth tokens of the profile from the credential store
+	if config, err := LoadProfileConfig(profile); err == nil {
+		if store, err := GetCredentialStore(config); err == nil {
+			for _, key := range credentialKeys() {
+				_ = store.Delete(credentialAccount(profile, key.Name))
+			}
+		}
+	}
+
+	if err := os.Remove(profilePath); err != nil {
+		return fmt.Errorf("error removing profile: %v", err)
+	}
+
+	// reset the selected profile if it was removed
+	envFilePath, err := ConfigFilePath()
+	if err != nil {
+		return err
+	}
+
+	if fileExists(envFilePath) {
+		v, err := readEnvFile(envFilePath)
+		if err != nil {
+			return err
+		}
+
+		if v.GetString(profileKey) == profile {
+			return UseProfile(DefaultProfile)
+		}
+	}
+
+	return nil
+}
+
+func profileExists(profile string) (bool, error) {
+	profilePath, err := ProfileFilePath(profile)
+	if err != nil {
+		return false, err
+	}
+
+	return fileExists(profilePath), nil
+}
+
+func validateProfileName(profile string) error {
+	if !profileNameRegex.MatchString(profile) {
+		return fmt.Errorf("Invalid profile name '%s'. Only letters, numbers, '-' and '_' are allowed.\n", profile)
+	}
+
+	return nil
+}
@@ -0,0 +1,310 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/sha3"
+	"golang.org/x/term"
+)
+
+const (
+	privateKeyEnvVar       = "EVMR_PRIVATE_KEY"
+	keystorePasswordEnvVar = "EVMR_KEYSTORE_PASSWORD"
+
+	// Optimism, where addresses are linked and NFTs are minted
+	siweChainID = 10
+)
+
+// signs in with an Ethereum account (EIP-4361) by signing a nonce issued by the server
+type ethereumProvider struct{}
+
+func (ethereumProvider) Name() string {
+	return "ethereum"
+}
+
+func (ethereumProvider) DisplayName() string {
+	return "Ethereum"
+}
+
+func (ethereumProvider) Aliases() []string {
+	return []string{"eth", "siwe", "wallet"}
+}
+
+func (ethereumProvider) Authenticate(config Config, opts AuthOptions) (AuthResponse, error) {
+	var authResp AuthResponse
+
+	key, err := loadEthereumKey(opts)
+	if err != nil {
+		return authResp, err
+	}
+
+	address := ethereumAddress(key.PubKey())
+	fmt.Printf("Signing in as %s\n", address)
+
+	// get a nonce from the server, which prevents replaying signatures
+	resp, err := http.Get(config.EVMR_SERVER + "auth/siwe/nonce")
+	if err != nil {
+		return authResp, fmt.Errorf("error making GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return authResp, fmt.Errorf("the server doesn't support signing in with Ethereum")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return authResp, fmt.Errorf("failed to get nonce from server: %s", resp.Status)
+	}
+
+	var nonceResp struct {
+		Nonce string `json:"nonce"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&nonceResp); err != nil {
+		return authResp, fmt.Errorf("error unmarshalling response body: %v", err)
+	}
+
+	message, err := siweMessage(config.EVMR_SERVER, address, nonceResp.Nonce, time.Now().UTC())
+	if err != nil {
+		return authResp, err
+	}
+
+	payload, _ := json.Marshal(map[string]string{
+		"message":   message,
+		"signature": "0x" + hex.EncodeToString(signPersonalMessage(key, message)),
+	})
+
+	resp, err = http.Post(config.EVMR_SERVER+"auth/siwe", "application/json", bytes.NewBuffer(payload))
+	if err != nil {
+		return authResp, fmt.Errorf("error making POST request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return authResp, fmt.Errorf("failed to authenticate with server: %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil {
+		return authResp, fmt.Errorf("error unmarshalling response body: %v", err)
+	}
+
+	return authResp, nil
+}
+
+// builds a Sign-In with Ethereum message as specified in EIP-4361
+func siweMessage(server string, address string, nonce string, issuedAt time.Time) (string, error) {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL '%s': %v", server, err)
+	}
+
+	return fmt.Sprintf(`%s wants you to sign in with your Ethereum account:
+%s
+
+Sign in to evm-runners.
+
+URI: %s
+Version: 1
+Chain ID: %d
+Nonce: %s
+Issued At: %s`, serverURL.Host, address, server, siweChainID, nonce, issuedAt.Format(time.RFC3339)), nil
+}
+
+// loads the private key from a keystore file, a key file, EVMR_PRIVATE_KEY, or asks for a keystore file
+func loadEthereumKey(opts AuthOptions) (*secp256k1.PrivateKey, error) {
+	if opts.Keystore != "" {
+		return loadKeystore(opts.Keystore)
+	}
+
+	if opts.KeyFile != "" {
+		data, err := os.ReadFile(opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading key file: %v", err)
+		}
+		return parsePrivateKey(string(data))
+	}
+
+	if key := os.Getenv(privateKeyEnvVar); key != "" {
+		return parsePrivateKey(key)
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("no key provided. Use '--keystore', '--key-file' or set %s", privateKeyEnvVar)
+	}
+
+	fmt.Printf("Path to your keystore file: ")
+	path, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore path: %v", err)
+	}
+
+	return loadKeystore(strings.TrimSpace(path))
+}
+
+func parsePrivateKey(key string) (*secp256k1.PrivateKey, error) {
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(key), "0x"))
+	if err != nil || len(keyBytes) != 32 {
+		return nil, fmt.Errorf("invalid private key, expected 32 hex encoded bytes")
+	}
+
+	return secp256k1.PrivKeyFromBytes(keyBytes), nil
+}
+
+// encrypted key file in the Web3 Secret Storage format, as created by geth, cast or MetaMask exports
+type keystoreFile struct {
+	Crypto struct {
+		Cipher       string `json:"cipher"`
+		CipherText   string `json:"ciphertext"`
+		CipherParams struct {
+			IV string `json:"iv"`
+		} `json:"cipherparams"`
+		KDF       string `json:"kdf"`
+		KDFParams struct {
+			N     int    `json:"n"`
+			R     int    `json:"r"`
+			P     int    `json:"p"`
+			C     int    `json:"c"`
+			PRF   string `json:"prf"`
+			DKLen int    `json:"dklen"`
+			Salt  string `json:"salt"`
+		} `json:"kdfparams"`
+		MAC string `json:"mac"`
+	} `json:"crypto"`
+}
+
+// decrypts a keystore file with a password from EVMR_KEYSTORE_PASSWORD or the terminal
+func loadKeystore(path string) (*secp256k1.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading keystore file: %v", err)
+	}
+
+	var keystore keystoreFile
+	if err := json.Unmarshal(data, &keystore); err != nil {
+		return nil, fmt.Errorf("error parsing keystore file: %v", err)
+	}
+
+	password := os.Getenv(keystorePasswordEnvVar)
+	if password == "" {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return nil, fmt.Errorf("a password is needed to decrypt the keystore file, set %s", keystorePasswordEnvVar)
+		}
+
+		fmt.Printf("Keystore password: ")
+		pass, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read password: %v", err)
+		}
+		password = string(pass)
+	}
+
+	c := keystore.Crypto
+	salt, err := hex.DecodeString(c.KDFParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt in keystore file")
+	}
+
+	var derivedKey []byte
+	switch c.KDF {
+	case "scrypt":
+		derivedKey, err = scrypt.Key([]byte(password), salt, c.KDFParams.N, c.KDFParams.R, c.KDFParams.P, c.KDFParams.DKLen)
+		if err != nil {
+			return nil, fmt.Errorf("error deriving key: %v", err)
+		}
+	case "pbkdf2":
+		if c.KDFParams.PRF != "hmac-sha256" {
+			return nil, fmt.Errorf("unsupported keystore PRF '%s'", c.KDFParams.PRF)
+		}
+		derivedKey = pbkdf2.Key([]byte(password), salt, c.KDFParams.C, c.KDFParams.DKLen, sha256.New)
+	default:
+		return nil, fmt.Errorf("unsupported keystore KDF '%s'", c.KDF)
+	}
+
+	if len(derivedKey) < 32 {
+		return nil, fmt.Errorf("invalid key length in keystore file")
+	}
+
+	if c.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("unsupported keystore cipher '%s'", c.Cipher)
+	}
+
+	cipherText, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext in keystore file")
+	}
+
+	mac, err := hex.DecodeString(c.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAC in keystore file")
+	}
+
+	// the MAC verifies the password before decrypting
+	if subtle.ConstantTimeCompare(keccak256(derivedKey[16:32], cipherText), mac) != 1 {
+		return nil, fmt.Errorf("wrong keystore password")
+	}
+
+	iv, err := hex.DecodeString(c.CipherParams.IV)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid IV in keystore file")
+	}
+
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, len(cipherText))
+	cipher.NewCTR(block, iv).XORKeyStream(key, cipherText)
+
+	return parsePrivateKey(hex.EncodeToString(key))
+}
+
+// signs a message as personal_sign does (EIP-191) and returns the signature as r || s || v
+func signPersonalMessage(key *secp256k1.PrivateKey, message string) []byte {
+	hash := keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+
+	// the compact signature is v || r || s with v = 27 + recovery id
+	sig := ecdsa.SignCompact(key, hash, false)
+
+	return append(sig[1:], sig[0])
+}
+
+// returns the EIP-55 checksummed address of a public key
+func ethereumAddress(pub *secp256k1.PublicKey) string {
+	addr := hex.EncodeToString(keccak256(pub.SerializeUncompressed()[1:])[12:])
+	hash := hex.EncodeToString(keccak256([]byte(addr)))
+
+	checksummed := []byte(addr)
+	for i, c := range checksummed {
+		if c >= 'a' && c <= 'f' && hash[i] >= '8' {
+			checksummed[i] = c - 'a' + 'A'
+		}
+	}
+
+	return "0x" + string(checksummed)
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// private key and address of the web3.js documentation
+const (
+	testPrivateKey = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testAddress    = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+)
+
+func TestEthereumAddress(t *testing.T) {
+	key, err := parsePrivateKey(testPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ethereumAddress(key.PubKey()); got != testAddress {
+		t.Errorf("ethereumAddress() = %s, want %s", got, testAddress)
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	for _, key := range []string{"", "0x1234", "not hex", testPrivateKey + "00"} {
+		if _, err := parsePrivateKey(key); err == nil {
+			t.Errorf("parsePrivateKey(%q) accepted an invalid key", key)
+		}
+	}
+
+	if _, err := parsePrivateKey(strings.TrimPrefix(testPrivateKey, "0x") + "\n"); err != nil {
+		t.Errorf("parsePrivateKey() without 0x prefix error = %v", err)
+	}
+}
+
+// recovers the address that signed a message with personal_sign
+func recoverSigner(t *testing.T, message string, signature []byte) string {
+	t.Helper()
+
+	if len(signature) != 65 {
+		t.Fatalf("signature has %d bytes, want 65", len(signature))
+	}
+
+	// r || s || v to the compact format v || r || s
+	compact := append([]byte{signature[64]}, signature[:64]...)
+	hash := keccak256([]byte("\x19Ethereum Signed Message:\n" + strconv.Itoa(len(message)) + message))
+
+	pub, _, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		t.Fatalf("error recovering signer: %v", err)
+	}
+
+	return ethereumAddress(pub)
+}
+
+func TestSignPersonalMessage(t *testing.T) {
+	key, _ := parsePrivateKey(testPrivateKey)
+
+	signature := signPersonalMessage(key, "Some data")
+
+	// signature of the web3.js documentation for the same key and message
+	want := "b91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c"
+	if got := hex.EncodeToString(signature); got != want {
+		t.Errorf("signPersonalMessage() = %s, want %s", got, want)
+	}
+
+	if signer := recoverSigner(t, "Some data", signature); signer != testAddress {
+		t.Errorf("recovered signer %s, want %s", signer, testAddress)
+	}
+}
+
+func TestSiweMessage(t *testing.T) {
+	issuedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	message, err := siweMessage("https://api.evmr.sh/", testAddress, "nonce123", issuedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `api.evmr.sh wants you to sign in with your Ethereum account:
+0x2c7536E3605D9C16a7a3D7b1898e529396a65c23
+
+Sign in to evm-runners.
+
+URI: https://api.evmr.sh/
+Version: 1
+Chain ID: 10
+Nonce: nonce123
+Issued At: 2024-01-02T03:04:05Z`
+	if message != want {
+		t.Errorf("siweMessage() =\n%s\nwant\n%s", message, want)
+	}
+}
+
+func TestEthereumProvider(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/siwe/nonce", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"nonce": "n0nce"})
+	})
+	mux.HandleFunc("/auth/siwe", func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Message   string `json:"message"`
+			Signature string `json:"signature"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		signature, err := hex.DecodeString(strings.TrimPrefix(payload.Signature, "0x"))
+		if err != nil || !strings.Contains(payload.Message, "Nonce: n0nce\n") || recoverSigner(t, payload.Message, signature) != testAddress {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(AuthResponse{ID: "5", Name: testAddress, AccessToken: "access"})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Setenv(privateKeyEnvVar, testPrivateKey)
+
+	provider, _ := GetAuthProvider("ethereum")
+	authResp, err := provider.Authenticate(Config{EVMR_SERVER: server.URL + "/"}, AuthOptions{})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if authResp.AccessToken != "access" {
+		t.Errorf("Authenticate() = %+v", authResp)
+	}
+}
+
+// keystore with the private key 7a28b5ba..., test vectors of the Web3 Secret Storage definition, password "testpassword"
+const (
+	testKeystorePrivateKey = "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d"
+
+	testKeystorePBKDF2 = `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},
+"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2",
+"kdfparams":{"c":262144,"dklen":32,"prf":"hmac-sha256","salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},
+"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},"version":3}`
+
+	testKeystoreScrypt = `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"83dbcc02d8ccb40e466191a123791e0e"},
+"ciphertext":"d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c","kdf":"scrypt",
+"kdfparams":{"dklen":32,"n":262144,"r":1,"p":8,"salt":"ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},
+"mac":"2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"},"version":3}`
+)
+
+func writeKeystore(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadKeystore(t *testing.T) {
+	t.Setenv(keystorePasswordEnvVar, "testpassword")
+
+	for name, keystore := range map[string]string{"pbkdf2": testKeystorePBKDF2, "scrypt": testKeystoreScrypt} {
+		key, err := loadKeystore(writeKeystore(t, keystore))
+		if err != nil {
+			t.Errorf("loadKeystore(%s) error = %v", name, err)
+			continue
+		}
+		if got := hex.EncodeToString(key.Serialize()); got != testKeystorePrivateKey {
+			t.Errorf("loadKeystore(%s) = %s, want %s", name, got, testKeystorePrivateKey)
+		}
+	}
+}
+
+func TestLoadKeystoreErrors(t *testing.T) {
+	t.Setenv(keystorePasswordEnvVar, "wrongpassword")
+	if _, err := loadKeystore(writeKeystore(t, testKeystorePBKDF2)); err == nil || !strings.Contains(err.Error(), "wrong keystore password") {
+		t.Errorf("loadKeystore() with a wrong password error = %v", err)
+	}
+
+	// the MAC doesn't cover the IV, so an invalid IV is only noticed when decrypting
+	t.Setenv(keystorePasswordEnvVar, "testpassword")
+	shortIV := strings.Replace(testKeystorePBKDF2, "6087dab2f9fdbbfaddc31a909735c1e6", "6087dab2", 1)
+	if _, err := loadKeystore(writeKeystore(t, shortIV)); err == nil || !strings.Contains(err.Error(), "invalid IV") {
+		t.Errorf("loadKeystore() with a short IV error = %v", err)
+	}
+
+	unsupported := strings.Replace(testKeystorePBKDF2, `"kdf":"pbkdf2"`, `"kdf":"argon2"`, 1)
+	if _, err := loadKeystore(writeKeystore(t, unsupported)); err == nil {
+		t.Errorf("loadKeystore() accepted an unsupported KDF")
+	}
+}
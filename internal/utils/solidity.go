@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// inside foundry's cache directory, which is ignored by git
+	solidityOutDir = "cache/evmr"
+	sourceHashFile = ".source-hash"
+)
+
+// files of the levels directory that change how forge compiles, e.g. the optimizer settings and remappings
+var foundryConfigFiles = []string{"foundry.toml", "remappings.txt"}
+
+// compiles a single .sol solution (and its imports) into an isolated output directory and returns that directory.
+// Compilation is skipped if the solution, its local imports and the compiler args didn't change since the last build.
+func buildSolidity(levelsDir string, solPath string, filename string, args []string) (string, error) {
+	outDir := filepath.Join(levelsDir, filepath.FromSlash(solidityOutDir), filename)
+
+	sourceHash, err := hashSource(levelsDir, solPath, "sol")
+	if err != nil {
+		return "", err
+	}
+
+	// include the compiler args and the foundry config, changing them requires a new build
+	hasher := sha256.New()
+	hasher.Write([]byte(sourceHash))
+	hasher.Write([]byte(strings.Join(args, "\x00")))
+	hasher.Write([]byte(hashFoundryConfig(levelsDir)))
+	hash := hex.EncodeToString(hasher.Sum(nil))
+
+	// skip compilation if the source hasn't changed since the last build
+	hashPath := filepath.Join(outDir, sourceHashFile)
+	if previous, err := os.ReadFile(hashPath); err == nil && string(previous) == hash {
+		return outDir, nil
+	}
+
+	// remove stale artifacts before compiling
+	if err := os.RemoveAll(outDir); err != nil {
+		return "", fmt.Errorf("error removing old build output: %v", err)
+	}
+
+	buildArgs := []string{"build", solPath, "--out", outDir, "--cache-path", filepath.Join(outDir, "cache")}
+	execCmd := exec.Command("forge", append(buildArgs, args...)...)
+	execCmd.Dir = levelsDir
+	output, err := execCmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", err, output)
+	}
+
+	if err := os.WriteFile(hashPath, []byte(hash), 0644); err != nil {
+		return "", fmt.Errorf("error writing source hash: %v", err)
+	}
+
+	return outDir, nil
+}
+
+// hashes the foundry config files of the levels directory. Missing files are hashed as empty.
+func hashFoundryConfig(levelsDir string) string {
+	hasher := sha256.New()
+	for _, name := range foundryConfigFiles {
+		data, _ := os.ReadFile(filepath.Join(levelsDir, name))
+		hasher.Write([]byte(name))
+		hasher.Write([]byte{0})
+		hasher.Write(data)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// bytecode of a compiled contract, either an object with an "object" field (current forge)
+// or a plain hex string (legacy forge and hardhat style artifacts)
+type artifactBytecode struct {
+	Object string
+}
+
+func (b *artifactBytecode) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		b.Object = str
+		return nil
+	}
+
+	var obj struct {
+		Object string `json:"object"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("unexpected bytecode format: %v", err)
+	}
+	b.Object = obj.Object
+
+	return nil
+}
+
+// compiler artifact as written by forge, or solc standard json output for a single contract
+type solidityArtifact struct {
+	Bytecode *artifactBytecode `json:"bytecode"`
+	Evm      *struct {
+		Bytecode *artifactBytecode `json:"bytecode"`
+	} `json:"evm"`
+}
+
+// returns the creation bytecode of a contract from the build output directory
+func readSolidityBytecode(outDir string, filename string, contract string) (string, error) {
+	artifactPath := filepath.Join(outDir, fmt.Sprintf("%s.sol", filename), fmt.Sprintf("%s.json", contract))
+
+	if !fileExists(artifactPath) {
+		path, err := findArtifact(outDir, filename, contract)
+		if err != nil {
+			return "", err
+		}
+		artifactPath = path
+	}
+
+	file, err := os.ReadFile(artifactPath)
+	if err != nil {
+		return "", fmt.Errorf("error reading artifact '%s': %v", artifactPath, err)
+	}
+
+	var artifact solidityArtifact
+	if err := json.Unmarshal(file, &artifact); err != nil {
+		return "", fmt.Errorf("error parsing artifact '%s': %v", artifactPath, err)
+	}
+
+	var bytecode string
+	if artifact.Bytecode != nil {
+		bytecode = artifact.Bytecode.Object
+	} else if artifact.Evm != nil && artifact.Evm.Bytecode != nil {
+		bytecode = artifact.Evm.Bytecode.Object
+	}
+
+	if strings.TrimPrefix(bytecode, "0x") == "" {
+		return "", fmt.Errorf("artifact '%s' contains no bytecode for contract '%s'.\nMake sure '%s' is a deployable contract and not an interface or abstract contract.\n", artifactPath, contract, contract)
+	}
+
+	return bytecode, nil
+}
+
+// searches the build output directory for the artifact of a contract, e.g. if forge appended the compiler version
+// to the artifact name or placed it in a different directory
+func findArtifact(outDir string, filename string, contract string) (string, error) {
+	var matches []string
+	err := filepath.WalkDir(outDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// skip forge's cache directory
+			if path != outDir && d.Name() == "cache" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name := d.Name()
+		if name == contract+".json" || (strings.HasPrefix(name, contract+".") && strings.HasSuffix(name, ".json")) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("error searching for artifact of contract '%s': %v", contract, err)
+	}
+
+	expectedPath := filepath.Join(outDir, fmt.Sprintf("%s.sol", filename), fmt.Sprintf("%s.json", contract))
+
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("found multiple artifacts for contract '%s': %s\n", contract, strings.Join(matches, ", "))
+	}
+
+	// list the contracts that were compiled instead, a common mistake is renaming the solution contract
+	var found []string
+	entries, _ := os.ReadDir(filepath.Join(outDir, fmt.Sprintf("%s.sol", filename)))
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".json") {
+			found = append(found, strings.TrimSuffix(entry.Name(), ".json"))
+		}
+	}
+
+	if len(found) > 0 {
+		return "", fmt.Errorf("artifact for contract '%s' not found at '%s'.\nThe solution file '%s.sol' contains: %s. Make sure your solution contract is named '%s'.\n", contract, expectedPath, filename, strings.Join(found, ", "), contract)
+	}
+
+	return "", fmt.Errorf("artifact for contract '%s' not found at '%s'.\nMake sure '%s.sol' contains a contract named '%s'.\n", contract, expectedPath, filename, contract)
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadSolidityBytecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "legacy string bytecode",
+			files: map[string]string{"Average.sol/Average.json": `{"bytecode": "0x6001"}`},
+			want:  "0x6001",
+		},
+		{
+			name:  "bytecode object",
+			files: map[string]string{"Average.sol/Average.json": `{"bytecode": {"object": "0x6002", "linkReferences": {}}}`},
+			want:  "0x6002",
+		},
+		{
+			name:  "solc evm.bytecode",
+			files: map[string]string{"Average.sol/Average.json": `{"abi": [], "evm": {"bytecode": {"object": "6003"}}}`},
+			want:  "6003",
+		},
+		{
+			name: "artifact with compiler version",
+			files: map[string]string{
+				"Average.sol/Average.0.8.19.json": `{"bytecode": {"object": "0x6004"}}`,
+				"cache/Average.json":              `{"bytecode": {"object": "0xbad"}}`,
+			},
+			want: "0x6004",
+		},
+		{
+			name:  "artifact in another directory",
+			files: map[string]string{"solutions/Average.sol/Average.json": `{"bytecode": {"object": "0x6005"}}`},
+			want:  "0x6005",
+		},
+		{
+			name: "multiple artifacts",
+			files: map[string]string{
+				"Average.sol/Average.0.8.19.json": `{"bytecode": {"object": "0x6006"}}`,
+				"Average.sol/Average.0.8.20.json": `{"bytecode": {"object": "0x6007"}}`,
+			},
+			wantErr: "found multiple artifacts for contract 'Average'",
+		},
+		{
+			name:    "wrong contract name",
+			files:   map[string]string{"Average.sol/MyAverage.json": `{"bytecode": {"object": "0x6008"}}`},
+			wantErr: "contains: MyAverage. Make sure your solution contract is named 'Average'",
+		},
+		{
+			name:    "no artifacts",
+			wantErr: "Make sure 'Average.sol' contains a contract named 'Average'",
+		},
+		{
+			name:    "interface",
+			files:   map[string]string{"Average.sol/Average.json": `{"bytecode": {"object": "0x"}}`},
+			wantErr: "contains no bytecode for contract 'Average'",
+		},
+		{
+			name:    "invalid json",
+			files:   map[string]string{"Average.sol/Average.json": `{"bytecode": 1}`},
+			wantErr: "error parsing artifact",
+		},
+	}
+
+	for _, tt := range tests {
+		outDir := t.TempDir()
+		for path, content := range tt.files {
+			writeTestFile(t, filepath.Join(outDir, filepath.FromSlash(path)), content)
+		}
+
+		got, err := readSolidityBytecode(outDir, "Average", "Average")
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error = %v, want error containing %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: error = %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: bytecode = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+const (
+	// default solutions directory is next to the levels directory, e.g. ./evm-runners-solutions
+	solutionsDirSuffix = "-solutions"
+
+	backupsDir = "backups"
+	// number of src/ backups that are kept
+	maxBackups = 5
+)
+
+// returns the directory of the user's solutions. Solutions used to live in the src/ directory of the levels
+// repository, where updates could overwrite them, see MigrateSolutions. The directory is created when the first
+// template is copied.
+func SolutionsDir(config Config) (string, error) {
+	if config.EVMR_LEVELS_DIR == "" {
+		return "", fmt.Errorf("EVMR_LEVELS_DIR is not set. Please run 'evmr init' first!\n")
+	}
+
+	dir := solutionsDir(config)
+
+	// solutions that weren't moved yet would not be found
+	if !fileExists(dir) {
+		if files, err := userSourceFiles(config.EVMR_LEVELS_DIR); err == nil && len(files) > 0 {
+			return "", fmt.Errorf("Your solutions are still in '%s'. Run 'evmr update' to move them to '%s'.\n", filepath.Join(config.EVMR_LEVELS_DIR, solutionDir), dir)
+		}
+	}
+
+	return dir, nil
+}
+
+func solutionsDir(config Config) string {
+	if config.EVMR_SOLUTIONS_DIR != "" {
+		return config.EVMR_SOLUTIONS_DIR
+	}
+
+	return filepath.Clean(config.EVMR_LEVELS_DIR) + solutionsDirSuffix
+}
+
+// moves the user's files from the src/ directory of the levels repository to the solutions directory the first time
+// it is used, and returns a message about the moved files for the user. Called before the levels are installed or updated.
+func MigrateSolutions(config Config) (string, error) {
+	if config.EVMR_LEVELS_DIR == "" {
+		return "", fmt.Errorf("EVMR_LEVELS_DIR is not set. Please run 'evmr init' first!\n")
+	}
+
+	dir := solutionsDir(config)
+	if fileExists(dir) {
+		return "", nil
+	}
+
+	return migrateSolutions(config.EVMR_LEVELS_DIR, dir)
+}
+
+// returned by CopyTemplate if the solution file already exists
+var ErrSolutionExists = errors.New("solution file already exists")
+
+// copies the template file of a level, e.g. Average.huff, to the solutions directory and returns the path of the copy.
+// An existing solution file is only overwritten if overwrite is set.
+func CopyTemplate(levelsDir string, solutionsDir string, templateFile string, overwrite bool) (string, error) {
+	src := filepath.Join(levelsDir, "template", templateFile)
+	dst := filepath.Join(solutionsDir, templateFile)
+
+	if !overwrite && fileExists(dst) {
+		return dst, ErrSolutionExists
+	}
+
+	if err := os.MkdirAll(solutionsDir, 0755); err != nil {
+		return dst, fmt.Errorf("error creating solutions directory: %v", err)
+	}
+
+	input, err := os.ReadFile(src)
+	if err != nil {
+		return dst, fmt.Errorf("error copying file: %v", err)
+	}
+	if err := os.WriteFile(dst, input, 0644); err != nil {
+		return dst, fmt.Errorf("error copying file: %v", err)
+	}
+
+	return dst, nil
+}
+
+// returns the path of a solution file, e.g. <solutions dir>/Average.huff
+func solutionPath(solutionsDir string, filename string, solutionType string) string {
+	return filepath.Join(solutionsDir, fmt.Sprintf("%s.%s", filename, solutionType))
+}
+
+// moves the user's files from the src/ directory of the levels repository to the solutions directory.
+// Files tracked by the levels repository are left in place.
+func migrateSolutions(levelsDir string, solutionsDir string) (string, error) {
+	if err := os.MkdirAll(solutionsDir, 0755); err != nil {
+		return "", fmt.Errorf("error creating solutions directory: %v", err)
+	}
+
+	files, err := userSourceFiles(levelsDir)
+	if err != nil || len(files) == 0 {
+		return "", err
+	}
+
+	for _, file := range files {
+		rel := strings.TrimPrefix(file, solutionDir+"/")
+		if err := movePath(filepath.Join(levelsDir, filepath.FromSlash(file)), filepath.Join(solutionsDir, filepath.FromSlash(rel))); err != nil {
+			return "", fmt.Errorf("error moving '%s' to the solutions directory: %v", file, err)
+		}
+		// remove directories that are empty now
+		_ = removeFile(levelsDir, filepath.FromSlash(file))
+	}
+
+	return fmt.Sprintf("Moved %d file(s) from '%s' to '%s'.\nYour solutions are now kept outside of the levels directory, so updates can't overwrite them.\n", len(files), filepath.Join(levelsDir, solutionDir), solutionsDir), nil
+}
+
+// returns the files in src/ that are not tracked by the levels repository, as slash separated paths.
+// Ignored files are included, e.g. solutions matched by a .gitignore of the levels repository.
+func userSourceFiles(levelsDir string) ([]string, error) {
+	srcDir := filepath.Join(levelsDir, solutionDir)
+	if !fileExists(srcDir) {
+		return nil, nil
+	}
+
+	// without a repository, everything except placeholders belongs to the user
+	tracked := map[string]bool{solutionDir + "/.gitkeep": true}
+
+	if repo, err := git.PlainOpen(levelsDir); err == nil {
+		index, err := repo.Storer.Index()
+		if err != nil {
+			return nil, fmt.Errorf("error checking the levels repository: %v", err)
+		}
+
+		tracked = make(map[string]bool)
+		for _, entry := range index.Entries {
+			tracked[entry.Name] = true
+		}
+	}
+
+	var files []string
+	err := filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(levelsDir, path)
+		if err != nil {
+			return err
+		}
+
+		if rel = filepath.ToSlash(rel); !tracked[rel] {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// copies the src/ directory of the levels repository of a pack to the backups directory and returns the backup directory,
+// e.g. backups/official/20240102-150405.000000-123456/src. Only the latest backups of each pack are kept.
+// Returns an empty string if there was nothing to back up.
+func BackupSources(levelsDir string, pack string) (string, error) {
+	srcDir := filepath.Join(levelsDir, solutionDir)
+	if !fileExists(srcDir) {
+		return "", nil
+	}
+
+	dataDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	backups := filepath.Join(dataDir, backupsDir, pack)
+	if err := os.MkdirAll(backups, 0755); err != nil {
+		return "", fmt.Errorf("error creating backups directory: %v", err)
+	}
+
+	// names sort by date, the random suffix keeps backups of the same time apart
+	backupRoot, err := os.MkdirTemp(backups, time.Now().Format("20060102-150405.000000")+"-")
+	if err != nil {
+		return "", fmt.Errorf("error creating backup directory: %v", err)
+	}
+	backup := filepath.Join(backupRoot, solutionDir)
+
+	copied := 0
+	err = filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		copied++
+		return copyPath(path, filepath.Join(backup, rel))
+	})
+	if err != nil {
+		os.RemoveAll(backupRoot)
+		return "", fmt.Errorf("error backing up '%s': %v", srcDir, err)
+	}
+
+	if copied == 0 {
+		os.Remove(backupRoot)
+		return "", nil
+	}
+
+	// the backup names sort by date, remove the oldest ones
+	entries, err := os.ReadDir(backups)
+	if err == nil && len(entries) > maxBackups {
+		for _, entry := range entries[:len(entries)-maxBackups] {
+			_ = os.RemoveAll(filepath.Join(backups, entry.Name()))
+		}
+	}
+
+	return backupRoot, nil
+}
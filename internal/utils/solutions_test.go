@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestBackupSourcesDontCollide(t *testing.T) {
+	home := setupTestHome(t)
+
+	official := filepath.Join(home, "levels")
+	community := filepath.Join(home, "community")
+	writeTestFile(t, filepath.Join(official, solutionDir, "Average.sol"), "official")
+	writeTestFile(t, filepath.Join(community, solutionDir, "Average.sol"), "community")
+
+	// backups made in the same second must not overwrite each other
+	backups := make(map[string]string)
+	for _, b := range []struct{ dir, pack string }{{official, OfficialPack}, {community, "community"}, {official, OfficialPack}} {
+		backup, err := BackupSources(b.dir, b.pack)
+		if err != nil {
+			t.Fatalf("BackupSources(%s) error = %v", b.pack, err)
+		}
+		if _, ok := backups[backup]; ok {
+			t.Fatalf("backup '%s' was used twice", backup)
+		}
+		backups[backup] = b.pack
+	}
+
+	for backup, pack := range backups {
+		data, err := os.ReadFile(filepath.Join(backup, solutionDir, "Average.sol"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pack == OfficialPack && string(data) != "official" || pack == "community" && string(data) != "community" {
+			t.Errorf("backup '%s' of %s contains %q", backup, pack, data)
+		}
+	}
+}
+
+func TestBackupSourcesKeepsLatest(t *testing.T) {
+	home := setupTestHome(t)
+	levelsDir := filepath.Join(home, "levels")
+	writeTestFile(t, filepath.Join(levelsDir, solutionDir, "Average.sol"), "")
+
+	for i := 0; i < maxBackups+2; i++ {
+		if _, err := BackupSources(levelsDir, OfficialPack); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(home, backupsDir, OfficialPack))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != maxBackups {
+		t.Errorf("%d backups kept, want %d", len(entries), maxBackups)
+	}
+}
+
+func TestBackupSourcesWithoutFiles(t *testing.T) {
+	home := setupTestHome(t)
+	levelsDir := filepath.Join(home, "levels")
+	if err := os.MkdirAll(filepath.Join(levelsDir, solutionDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := BackupSources(levelsDir, OfficialPack)
+	if err != nil || backup != "" {
+		t.Errorf("BackupSources() = %q, %v, want no backup", backup, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(home, backupsDir, OfficialPack)); len(entries) != 0 {
+		t.Errorf("empty backup directory was left behind")
+	}
+}
+
+// creates a levels repository with a tracked level in src/ and a .gitignore that ignores Huff files
+func initLevelsRepo(t *testing.T, levelsDir string) {
+	t.Helper()
+
+	writeTestFile(t, filepath.Join(levelsDir, solutionDir, "Average.sol"), "tracked")
+	writeTestFile(t, filepath.Join(levelsDir, ".gitignore"), "*.huff\n")
+
+	repo, err := git.PlainInit(levelsDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{".gitignore", solutionDir + "/Average.sol"} {
+		if _, err := wt.Add(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	if _, err := wt.Commit("levels", &git.CommitOptions{Author: signature}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUserSourceFilesIncludesIgnoredFiles(t *testing.T) {
+	levelsDir := t.TempDir()
+	initLevelsRepo(t, levelsDir)
+
+	writeTestFile(t, filepath.Join(levelsDir, solutionDir, "Average.huff"), "ignored solution")
+	writeTestFile(t, filepath.Join(levelsDir, solutionDir, "sub", "Helper.sol"), "untracked solution")
+
+	files, err := userSourceFiles(levelsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"src/Average.huff", "src/sub/Helper.sol"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("userSourceFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestMigrateSolutions(t *testing.T) {
+	home := setupTestHome(t)
+	levelsDir := filepath.Join(home, "levels")
+	initLevelsRepo(t, levelsDir)
+	writeTestFile(t, filepath.Join(levelsDir, solutionDir, "Average.huff"), "solution")
+
+	config := Config{EVMR_LEVELS_DIR: levelsDir}
+	solutionsDir := levelsDir + solutionsDirSuffix
+
+	// read-only callers don't move files, but point to the migration
+	if _, err := SolutionsDir(config); err == nil || !strings.Contains(err.Error(), "evmr update") {
+		t.Errorf("SolutionsDir() error = %v, want a hint to run 'evmr update'", err)
+	}
+	if fileExists(solutionsDir) {
+		t.Fatalf("SolutionsDir() created the solutions directory")
+	}
+
+	message, err := MigrateSolutions(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(message, "Moved 1 file(s)") {
+		t.Errorf("MigrateSolutions() message = %q", message)
+	}
+	if !fileExists(filepath.Join(solutionsDir, "Average.huff")) || fileExists(filepath.Join(levelsDir, solutionDir, "Average.huff")) {
+		t.Errorf("solution was not moved")
+	}
+	if !fileExists(filepath.Join(levelsDir, solutionDir, "Average.sol")) {
+		t.Errorf("tracked file was moved")
+	}
+
+	dir, err := SolutionsDir(config)
+	if err != nil || dir != solutionsDir {
+		t.Errorf("SolutionsDir() = %q, %v, want %q", dir, err, solutionsDir)
+	}
+
+	// the migration only runs once
+	if message, err := MigrateSolutions(config); err != nil || message != "" {
+		t.Errorf("second MigrateSolutions() = %q, %v", message, err)
+	}
+}
+
+func TestCopyTemplateCreatesSolutionsDir(t *testing.T) {
+	levelsDir := t.TempDir()
+	solutionsDir := filepath.Join(t.TempDir(), "solutions")
+	writeTestFile(t, filepath.Join(levelsDir, "template", "Average.sol"), "template")
+
+	path, err := CopyTemplate(levelsDir, solutionsDir, "Average.sol", false)
+	if err != nil {
+		t.Fatalf("CopyTemplate() error = %v", err)
+	}
+
+	if _, err := CopyTemplate(levelsDir, solutionsDir, "Average.sol", false); err != ErrSolutionExists {
+		t.Errorf("CopyTemplate() of an existing solution error = %v, want ErrSolutionExists", err)
+	}
+
+	writeTestFile(t, path, "my solution")
+	if _, err := CopyTemplate(levelsDir, solutionsDir, "Average.sol", true); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "template" {
+		t.Errorf("solution was not overwritten")
+	}
+}
@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// file extensions of tarballs that can be used as level sources
+var tarballExtensions = []string{".tar.gz", ".tgz", ".tar"}
+
+// maximum time to download a tarball of levels
+const downloadTimeout = 5 * time.Minute
+
+// installs levels into dir from a source, which can be
+//   - a git repository URL or the path of a local git repository, cloned at ref (if set)
+//   - the path of a local directory without git, which is copied
+//   - the path or URL of a tarball (.tar.gz, .tgz or .tar), which is extracted
+//
+// Only levels installed from git repositories can be updated with 'evmr update'.
+func InstallLevels(source string, ref string, dir string) error {
+	info, err := os.Stat(source)
+	isLocal := err == nil
+
+	switch {
+	case isTarball(source):
+		if ref != "" {
+			return fmt.Errorf("'--ref' can only be used with git repositories\n")
+		}
+
+		if isLocal {
+			file, err := os.Open(source)
+			if err != nil {
+				return fmt.Errorf("error opening '%s': %v", source, err)
+			}
+			defer file.Close()
+
+			return extractTarball(file, dir)
+		}
+
+		client := &http.Client{
+			Timeout: downloadTimeout,
+		}
+
+		resp, err := client.Get(source)
+		if err != nil {
+			return fmt.Errorf("error downloading '%s': %v", source, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("error downloading '%s': %s", source, resp.Status)
+		}
+
+		return extractTarball(resp.Body, dir)
+
+	case isLocal && info.IsDir() && !fileExists(filepath.Join(source, ".git")):
+		if ref != "" {
+			return fmt.Errorf("'--ref' can only be used with git repositories\n")
+		}
+
+		return copyDir(source, dir)
+
+	case isLocal && !info.IsDir():
+		return fmt.Errorf("'%s' is not a directory or a tarball (%s)\n", source, strings.Join(tarballExtensions, ", "))
+
+	case isLocal:
+		// go-git needs an absolute path to clone a local repository
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return fmt.Errorf("error getting absolute path for '%s': %v", source, err)
+		}
+		return CloneRepository(abs, ref, dir)
+
+	default:
+		return CloneRepository(source, ref, dir)
+	}
+}
+
+func isTarball(source string) bool {
+	// ignore query strings of URLs
+	name := strings.ToLower(strings.SplitN(source, "?", 2)[0])
+	for _, ext := range tarballExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// extracts a (gzipped) tarball into dir. If all files are in a single top-level directory,
+// as in archives of GitHub releases, that directory is stripped.
+func extractTarball(r io.Reader, dir string) error {
+	reader := bufio.NewReader(r)
+
+	// gzip streams start with 0x1f 0x8b
+	var archive io.Reader = reader
+	if magic, err := reader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("error reading tarball: %v", err)
+		}
+		defer gz.Close()
+		archive = gz
+	}
+
+	// extract into a temporary directory first, so a broken tarball leaves nothing behind
+	tmpDir, err := os.MkdirTemp(filepath.Dir(filepath.Clean(dir)), ".evmr-levels-")
+	if err != nil {
+		return fmt.Errorf("error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tr := tar.NewReader(archive)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading tarball: %v", err)
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if name == "." || name == "pax_global_header" {
+			continue
+		}
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path '%s' in tarball\n", header.Name)
+		}
+
+		target := filepath.Join(tmpDir, filepath.FromSlash(name))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+
+			out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, header.FileInfo().Mode().Perm()|0600)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return fmt.Errorf("error extracting '%s': %v", header.Name, err)
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		default:
+			// links and special files are not needed for levels
+			continue
+		}
+	}
+
+	root := tmpDir
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		root = filepath.Join(tmpDir, entries[0].Name())
+	}
+
+	if len(entries) == 0 {
+		return fmt.Errorf("the tarball is empty\n")
+	}
+
+	return movePath(root, dir)
+}
+
+// copies a directory recursively
+func copyDir(src string, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		return copyPath(p, target)
+	})
+}
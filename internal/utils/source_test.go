@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// builds a gzipped tarball of regular files
+func testTarball(t *testing.T, files map[string]string) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &buf
+}
+
+func TestExtractTarballStripsTopLevelDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "levels")
+
+	tarball := testTarball(t, map[string]string{
+		"levels-1.0/levels.toml":      "levels",
+		"levels-1.0/src/Average.sol":  "solution",
+		"./levels-1.0/test/Average.t": "test",
+	})
+	if err := extractTarball(tarball, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"levels.toml", "src/Average.sol", "test/Average.t"} {
+		if !fileExists(filepath.Join(dir, filepath.FromSlash(name))) {
+			t.Errorf("%s was not extracted", name)
+		}
+	}
+}
+
+func TestExtractTarballRejectsPathTraversal(t *testing.T) {
+	for _, name := range []string{"../evil", "levels/../../evil", "/evil"} {
+		parent := t.TempDir()
+		dir := filepath.Join(parent, "levels")
+
+		tarball := testTarball(t, map[string]string{
+			"levels/levels.toml": "levels",
+			name:                 "evil",
+		})
+		err := extractTarball(tarball, dir)
+		if err == nil || !strings.Contains(err.Error(), "invalid path") {
+			t.Errorf("extractTarball with %q: error = %v, want an invalid path error", name, err)
+		}
+
+		if fileExists(filepath.Join(parent, "evil")) || fileExists("/evil") {
+			t.Errorf("extractTarball with %q wrote outside of the levels directory", name)
+		}
+		if fileExists(dir) {
+			t.Errorf("extractTarball with %q left a partial levels directory", name)
+		}
+		// the temporary directory is removed as well
+		if entries, _ := os.ReadDir(parent); len(entries) != 0 {
+			t.Errorf("extractTarball with %q left %d files behind", name, len(entries))
+		}
+	}
+}
+
+func TestCloneRepositoryRemovesPartialClone(t *testing.T) {
+	origin := t.TempDir()
+	initLevelsRepo(t, origin)
+
+	dir := filepath.Join(t.TempDir(), "levels")
+	if err := CloneRepository(origin, "no-such-ref", dir); err == nil {
+		t.Fatal("cloning a missing ref succeeded")
+	}
+	if fileExists(dir) {
+		t.Errorf("the partial clone was not removed")
+	}
+
+	if err := CloneRepository(origin, "master", dir); err != nil {
+		t.Fatal(err)
+	}
+	if !fileExists(filepath.Join(dir, solutionDir, "Average.sol")) {
+		t.Errorf("the branch was not checked out")
+	}
+}
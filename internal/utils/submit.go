@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// returned by Submit if the tests of the server failed for the solution
+var ErrBackendTestsFailed = errors.New("backend tests failed")
+
+// ranks and scores of a submitted solution on the leaderboards
+type SubmitResult struct {
+	GasRank  string
+	SizeRank string
+	Gas      int
+	Size     int
+}
+
+// returns the gas and size scores of the user's submission for a level, 0 if there is none
+func SubmittedScores(config *Config, level Level) (int, int, error) {
+	submissions, err := FetchSubmissionData(config)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var gas, size int
+	for _, item := range submissions {
+		if strings.EqualFold(item.LevelName, level.Contract) {
+			gas, _ = strconv.Atoi(item.Gas)
+			size, _ = strconv.Atoi(item.Size)
+		}
+	}
+
+	return gas, size, nil
+}
+
+// submits the bytecode of a solution to the server of the config
+func Submit(config *Config, level Level, bytecode string, solutionType string) (SubmitResult, error) {
+	var result SubmitResult
+
+	// Create a JSON payload
+	payload := map[string]string{
+		"bytecode": bytecode,
+		"type":     solutionType,
+		"user_id":  config.EVMR_ID,
+		"level_id": level.ID,
+	}
+	jsonPayload, _ := json.Marshal(payload)
+
+	// Make the HTTP request
+	url := config.EVMR_SERVER + "submissions"
+	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send the request
+	client := &http.Client{}
+	resp, body, err := DoAuthorized(config, req, client)
+	if err != nil {
+		return result, err
+	}
+
+	// Check for errors in the response
+	if resp.StatusCode != http.StatusOK {
+		// if status code is 400, the solution is not correct
+		if resp.StatusCode == http.StatusBadRequest {
+			return result, ErrBackendTestsFailed
+		}
+
+		return result, fmt.Errorf("http request failed with status: %s", resp.Status)
+	}
+
+	// Decode the JSON response as an array of objects
+	var response []map[string]interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return result, fmt.Errorf("error decoding response: %v", err)
+	}
+	if len(response) == 0 {
+		return result, fmt.Errorf("error decoding response: no submission returned")
+	}
+
+	// Extract the gas and size rank from the first object in the array
+	result.GasRank, _ = response[0]["gas_rank"].(string)
+	result.SizeRank, _ = response[0]["size_rank"].(string)
+
+	// Fetch updated submission data
+	result.Gas, result.Size, err = SubmittedScores(config, level)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
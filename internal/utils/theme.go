@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeHighContrast = "high-contrast"
+	ThemeNoColor      = "no-color"
+
+	BoxStyleUnicode = "unicode"
+	BoxStyleASCII   = "ascii"
+
+	// disables colors if set to a non-empty value, see https://no-color.org
+	noColorEnvVar = "NO_COLOR"
+
+	resetStyle = "\x1b[0m"
+)
+
+// colors and box style used for all output
+type Theme struct {
+	Name string
+
+	// escape sequences of the styles, empty if the style adds no escape sequences
+	muted string
+	bold  string
+	link  string
+	pass  string
+	warn  string
+	fail  string
+
+	// glamour style the level details are rendered with
+	MarkdownStyle string
+
+	Box BoxStyle
+}
+
+// characters used to draw boxes and to show keys in the help of the TUI
+type BoxStyle struct {
+	Name string
+
+	Horizontal  string
+	Vertical    string
+	TopLeft     string
+	TopRight    string
+	BottomLeft  string
+	BottomRight string
+
+	Up     string
+	Down   string
+	Left   string
+	Right  string
+	Enter  string
+	Cursor string
+}
+
+var themes = map[string]Theme{
+	ThemeDark: {
+		muted: "\x1b[90m", bold: "\x1b[1m", link: "\x1b[94m",
+		pass: "\x1b[32m", warn: "\x1b[33m", fail: "\x1b[31m",
+		MarkdownStyle: "dark",
+	},
+	// gray and yellow are hard to read on a white background
+	ThemeLight: {
+		muted: "\x1b[38;5;240m", bold: "\x1b[1m", link: "\x1b[34m",
+		pass: "\x1b[32m", warn: "\x1b[38;5;130m", fail: "\x1b[31m",
+		MarkdownStyle: "light",
+	},
+	// muted text uses the default color of the terminal
+	ThemeHighContrast: {
+		bold: "\x1b[1m", link: "\x1b[1;4m",
+		pass: "\x1b[1;92m", warn: "\x1b[1;93m", fail: "\x1b[1;91m",
+		MarkdownStyle: "dark",
+	},
+	ThemeNoColor: {
+		MarkdownStyle: "notty",
+	},
+}
+
+var boxStyles = map[string]BoxStyle{
+	BoxStyleUnicode: {
+		Horizontal: "─", Vertical: "│", TopLeft: "┌", TopRight: "┐", BottomLeft: "└", BottomRight: "┘",
+		Up: "↑", Down: "↓", Left: "←", Right: "→", Enter: "↩", Cursor: "█",
+	},
+	BoxStyleASCII: {
+		Horizontal: "-", Vertical: "|", TopLeft: "+", TopRight: "+", BottomLeft: "+", BottomRight: "+",
+		Up: "Up", Down: "Down", Left: "Left", Right: "Right", Enter: "Enter", Cursor: "_",
+	},
+}
+
+// theme used until LoadTheme is called
+var activeTheme = newTheme(ThemeDark, BoxStyleUnicode)
+
+func newTheme(name string, boxStyle string) Theme {
+	theme, ok := themes[name]
+	if !ok {
+		name = ThemeDark
+		theme = themes[name]
+	}
+	theme.Name = name
+
+	box, ok := boxStyles[boxStyle]
+	if !ok {
+		boxStyle = BoxStyleUnicode
+		box = boxStyles[boxStyle]
+	}
+	box.Name = boxStyle
+	theme.Box = box
+
+	// glamour's ascii style has no colors either, but doesn't use any unicode characters
+	if boxStyle == BoxStyleASCII && name == ThemeNoColor {
+		theme.MarkdownStyle = "ascii"
+	}
+
+	return theme
+}
+
+// returns the theme selected with EVMR_THEME and EVMR_BOX_STYLE
+func ActiveTheme() Theme {
+	return activeTheme
+}
+
+// selects the theme of the config files, environment variables and flags.
+// Unlike LoadConfig, the credential store isn't read, so the theme can be loaded before every command.
+func LoadTheme() {
+	values := make(map[string]string)
+
+	// config file and the config file of the active profile
+	var paths []string
+	if path, err := ConfigFilePath(); err == nil {
+		paths = append(paths, path)
+	}
+	if profile, err := ActiveProfile(); err == nil && profile != DefaultProfile {
+		if path, err := ProfileFilePath(profile); err == nil {
+			paths = append(paths, path)
+		}
+	}
+
+	for _, path := range paths {
+		if !fileExists(path) {
+			continue
+		}
+
+		v, err := readEnvFile(path)
+		if err != nil {
+			continue
+		}
+
+		for _, name := range []string{"EVMR_THEME", "EVMR_BOX_STYLE"} {
+			if v.IsSet(name) {
+				values[name] = v.GetString(name)
+			}
+		}
+	}
+
+	// environment variables
+	if noColorSet() {
+		values["EVMR_THEME"] = ThemeNoColor
+	}
+	for _, name := range []string{"EVMR_THEME", "EVMR_BOX_STYLE"} {
+		if value := os.Getenv(name); value != "" {
+			values[name] = value
+		}
+	}
+
+	// flags
+	for _, name := range []string{"EVMR_THEME", "EVMR_BOX_STYLE"} {
+		if flag, ok := configFlags[name]; ok {
+			values[name] = flag.value
+		}
+	}
+
+	activeTheme = newTheme(strings.ToLower(values["EVMR_THEME"]), strings.ToLower(values["EVMR_BOX_STYLE"]))
+}
+
+// reports whether colors are disabled with the NO_COLOR environment variable
+func noColorSet() bool {
+	return os.Getenv(noColorEnvVar) != ""
+}
+
+func (t Theme) style(style string, s string) string {
+	if style == "" {
+		return s
+	}
+	return style + s + resetStyle
+}
+
+// styles text of less importance, e.g. help and hints
+func (t Theme) Muted(s string) string {
+	return t.style(t.muted, s)
+}
+
+func (t Theme) Bold(s string) string {
+	return t.style(t.bold, s)
+}
+
+// styles links and handles
+func (t Theme) Link(s string) string {
+	return t.style(t.link, s)
+}
+
+func (t Theme) Pass(s string) string {
+	return t.style(t.pass, s)
+}
+
+func (t Theme) Warn(s string) string {
+	return t.style(t.warn, s)
+}
+
+func (t Theme) Fail(s string) string {
+	return t.style(t.fail, s)
+}
+
+func validateTheme(value string) (string, error) {
+	value = strings.ToLower(value)
+	if _, ok := themes[value]; ok {
+		return value, nil
+	}
+
+	return "", fmt.Errorf("Invalid theme '%s'. Use either 'dark', 'light', 'high-contrast' or 'no-color'.\n", value)
+}
+
+func validateBoxStyle(value string) (string, error) {
+	value = strings.ToLower(value)
+	if _, ok := boxStyles[value]; ok {
+		return value, nil
+	}
+
+	return "", fmt.Errorf("Invalid box style '%s'. Use either 'unicode' or 'ascii'.\n", value)
+}
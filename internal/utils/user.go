@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type UserInfo struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// returned if the server doesn't provide an endpoint, e.g. older server versions
+var ErrNotSupported = errors.New("not supported by server")
+
+// fetches the info of the authenticated user, which also verifies the auth token
+func FetchUserInfo(config *Config) (UserInfo, error) {
+	var user UserInfo
+
+	req, _ := http.NewRequest("GET", config.EVMR_SERVER+"users/me", nil)
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+	}
+
+	resp, body, err := DoAuthorized(config, req, client)
+	if err != nil {
+		return user, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return user, ErrNotSupported
+	default:
+		return user, fmt.Errorf("http request failed with status: %s", resp.Status)
+	}
+
+	if err := json.Unmarshal(body, &user); err != nil {
+		return user, fmt.Errorf("error parsing the response: %v", err)
+	}
+
+	return user, nil
+}
+
+// revokes the auth token on the server
+func RevokeToken(config Config) error {
+	req, _ := http.NewRequest("POST", config.EVMR_SERVER+"auth/revoke", nil)
+	req.Header.Set("Authorization", "Bearer "+config.EVMR_TOKEN)
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending the request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return ErrNotSupported
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		// the token is already invalid
+		return nil
+	default:
+		return fmt.Errorf("http request failed with status: %s", resp.Status)
+	}
+}
+
+// returns the expiry of a JWT auth token. The signature is not verified, this is only used for display.
+func TokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(claims.Exp, 0), true
+}